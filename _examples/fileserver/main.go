@@ -41,6 +41,8 @@ func main() {
 	})
 
 	ctx, stopFn := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopFn()
+
 	srv, err := serv.New(port,
 		serv.WithName("fileserver"),
 		serv.WithBaseContext(ctx),
@@ -49,19 +51,7 @@ func main() {
 	)
 	errors.FatalOnErr(err)
 
-	go func() {
-		defer stopFn()
-		if err := srv.Run(); err != nil {
-			log.Println("Server error:", err.Error())
-		}
-	}()
-	<-ctx.Done()
-
-	if err = srv.Shutdown(context.Background()); err != nil {
-		if !errors.Is(err, context.DeadlineExceeded) {
-			log.Printf("Shutdown error: %+v\n", err)
-		} else if err = srv.Close(); err != nil {
-			log.Printf("Close error: %+v\n", err)
-		}
+	if err = srv.RunContext(ctx); err != nil {
+		log.Printf("Server error: %+v\n", err)
 	}
 }
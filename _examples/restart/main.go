@@ -33,7 +33,6 @@ func main() {
 
 	var restartCount atomic.Uint64
 	chRestart := make(chan struct{}, 1)
-	chRestart <- struct{}{}
 
 	mux := serv.NewServeMux()
 	mux.HandleRoute(serv.Route{
@@ -60,9 +59,10 @@ func main() {
 			w.Header().Set("Content-Type", "text/html")
 			_, _ = w.Write([]byte(`<i>Restarting server...</i><br /><a href="/">show count</a>`))
 
-			go func() {
-				chRestart <- struct{}{}
-			}()
+			select {
+			case chRestart <- struct{}{}:
+			default: // restart already pending
+			}
 		}),
 	})
 
@@ -78,37 +78,18 @@ func main() {
 	)
 	errors.FatalOnErr(err)
 
-loop:
-	for {
-		select {
-		case <-chRestart:
-			if srv.State() == serv.StateStarted {
-				log.Println("restarting server...")
-				if err = srv.Shutdown(context.Background()); err != nil {
-					if !errors.Is(err, context.DeadlineExceeded) {
-						log.Printf("shutdown error while restarting: %+v\n", err)
-					}
-				}
-			}
-			if state := srv.State(); state != serv.StateStarted {
-				if state == serv.StateClosed {
-					restartCount.Add(1)
-				}
-				go func() {
-					if err := srv.Run(); err != nil {
-						log.Println("server error:", err.Error())
-					}
-				}()
+	go func() {
+		for range chRestart {
+			log.Println("restarting server...")
+			if err := srv.Restart(context.Background()); err != nil {
+				log.Printf("error while restarting: %+v\n", err)
+				continue
 			}
-
-		case <-ctx.Done():
-			break loop
+			restartCount.Add(1)
 		}
-	}
+	}()
 
-	if srv.State() == serv.StateStarted {
-		if err = srv.Shutdown(context.Background()); err != nil {
-			log.Printf("%+v\n", err)
-		}
+	if err = srv.RunContext(ctx); err != nil {
+		log.Printf("%+v\n", err)
 	}
 }
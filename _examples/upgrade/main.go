@@ -0,0 +1,70 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/serv"
+)
+
+// This program replaces itself with a new process, without dropping any
+// connections, after receiving a SIGHUP signal. It shuts down after receiving
+// a SIGINT or SIGTERM signal.
+
+func main() {
+	var port serv.Port = 8080
+
+	cli := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	cli.Var(&port, "port", "Server port")
+	_ = cli.Parse(os.Args[1:])
+
+	mux := serv.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "Hello from process %d!", os.Getpid())
+	})
+
+	ctx, stopFn := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopFn()
+
+	srv, err := serv.New(port, mux, serv.WithDefaultLogger())
+	errors.FatalOnErr(err)
+
+	upg := serv.NewUpgrader(srv)
+	go func() {
+		if err := srv.Run(); err != nil {
+			log.Println("Server error:", err.Error())
+		}
+	}()
+	// only signal the parent process to exit once this process is serving
+	if err = srv.WaitReady(ctx); err != nil {
+		log.Println("Server not ready:", err.Error())
+		return
+	}
+	if err = upg.Ready(); err != nil {
+		log.Println("Ready error:", err.Error())
+	}
+	go func() {
+		_ = upg.UpgradeOnSignal(ctx, syscall.SIGHUP)
+	}()
+
+	select {
+	case <-upg.Exit():
+		return
+	case <-ctx.Done():
+	}
+
+	if err = srv.Shutdown(context.Background()); err != nil {
+		log.Printf("Shutdown error: %+v\n", err)
+	}
+}
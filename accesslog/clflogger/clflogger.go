@@ -26,7 +26,9 @@ func (l *CommonLogger) LogAccess(_ context.Context, det accesslog.Details, req *
 	}
 
 	username := "-"
-	if req.URL != nil {
+	if det.ClientIdentity != nil && !det.ClientIdentity.IsZero() {
+		username = det.ClientIdentity.String()
+	} else if req.URL != nil {
 		if u := req.URL.User.Username(); u != "" {
 			username = u
 		}
@@ -35,7 +35,14 @@ func (l *logger) LogAccess(_ context.Context, det Details, req *http.Request) {
 		handlerName = "-"
 	}
 
-	l.Printf("%s: %s %s \"%s %s %s\" %d %db %s\n",
+	// the identity of an authenticated client is only appended when
+	// available, so the output of other requests remains unchanged
+	var clientIdentity string
+	if det.ClientIdentity != nil {
+		clientIdentity = " " + det.ClientIdentity.String()
+	}
+
+	l.Printf("%s: %s %s \"%s %s %s\" %d %db %s%s\n",
 		Message,
 		RemoteAddr(req),
 		handlerName,
@@ -45,6 +52,7 @@ func (l *logger) LogAccess(_ context.Context, det Details, req *http.Request) {
 		det.StatusCode,
 		det.BytesWritten,
 		det.Duration,
+		clientIdentity,
 	)
 }
 
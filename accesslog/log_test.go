@@ -5,9 +5,14 @@
 package accesslog
 
 import (
+	"bytes"
+	"context"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/go-pogo/serv"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,3 +26,22 @@ func TestDefaultLogger(t *testing.T) {
 	want := log.Default()
 	assert.Same(t, want, DefaultLogger().(*logger).Logger)
 }
+
+func TestLogger_LogAccess(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	det := Details{StatusCode: http.StatusOK, BytesWritten: 2}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		var buf bytes.Buffer
+		NewLogger(log.New(&buf, "", 0)).LogAccess(context.Background(), det, req)
+		assert.Equal(t, "access request: 192.0.2.1 - \"GET / HTTP/1.1\" 200 2b 0s\n", buf.String())
+	})
+	t.Run("client identity", func(t *testing.T) {
+		det := det
+		det.ClientIdentity = &serv.Identity{SPIFFEID: "spiffe://example.org/sa/billing"}
+
+		var buf bytes.Buffer
+		NewLogger(log.New(&buf, "", 0)).LogAccess(context.Background(), det, req)
+		assert.Equal(t, "access request: 192.0.2.1 - \"GET / HTTP/1.1\" 200 2b 0s spiffe://example.org/sa/billing\n", buf.String())
+	})
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"encoding"
+	"flag"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-pogo/errors"
+)
+
+const ErrInvalidAddress errors.Msg = "invalid address"
+
+const (
+	networkTCP  = "tcp"
+	networkTCP4 = "tcp4"
+	networkTCP6 = "tcp6"
+	networkUnix = "unix"
+)
+
+var (
+	_ Option                   = (*Address)(nil)
+	_ encoding.TextMarshaler   = (*Address)(nil)
+	_ encoding.TextUnmarshaler = (*Address)(nil)
+	_ flag.Value               = (*Address)(nil)
+)
+
+// Address is a network qualified address a [Server] or [Endpoint] can listen
+// on. Its string form is one of:
+//   - "host:port", to listen on a TCP address;
+//   - "tcp4://host:port" or "tcp6://[host]:port", to listen on an IPv4 or
+//     IPv6 only TCP address;
+//   - "unix:/path/to/socket", to listen on a unix domain socket.
+//
+// The string form can be used as value in [Server.Addr] and [Endpoint.Addr].
+type Address struct {
+	// Network is either "tcp", "tcp4", "tcp6" or "unix".
+	Network string
+	// Addr is the TCP address of the form "host:port", or the path of the
+	// unix domain socket.
+	Addr string
+}
+
+// ParseAddress parses string s into an [Address]. Strings without a network
+// prefix are parsed as TCP address. An [ErrInvalidAddress] error is returned
+// when the network is unknown or the path of a unix domain socket is missing.
+func ParseAddress(s string) (Address, error) {
+	network, addr, ok := strings.Cut(s, "://")
+	if !ok {
+		if rest, isUnix := strings.CutPrefix(s, networkUnix+":"); isUnix {
+			network, addr = networkUnix, rest
+		} else {
+			network, addr = networkTCP, s
+		}
+	}
+
+	switch network {
+	case networkTCP, networkTCP4, networkTCP6:
+	case networkUnix:
+		if addr == "" {
+			return Address{}, errors.Newf("%w %q: missing socket path", ErrInvalidAddress, s)
+		}
+	default:
+		return Address{}, errors.Newf("%w %q: unknown network %q", ErrInvalidAddress, s, network)
+	}
+	return Address{Network: network, Addr: addr}, nil
+}
+
+// IsUnix indicates if the [Address] is a unix domain socket.
+func (a Address) IsUnix() bool { return a.Network == networkUnix }
+
+// Set parses string s into the [Address] using [ParseAddress].
+// This method implements the [flag.Value] interface.
+func (a *Address) Set(s string) (err error) {
+	if s == "" {
+		return nil
+	}
+
+	*a, err = ParseAddress(s)
+	return err
+}
+
+// UnmarshalText unmarshals text into [Address] using [ParseAddress].
+// This method implements the [encoding.TextUnmarshaler] interface.
+func (a *Address) UnmarshalText(text []byte) (err error) {
+	if len(text) == 0 {
+		return nil
+	}
+
+	*a, err = ParseAddress(string(text))
+	return err
+}
+
+// MarshalText marshals [Address] into a byte slice using [Address.String].
+// This method implements the [encoding.TextMarshaler] interface.
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// String returns the string form of the [Address], which can be parsed again
+// using [ParseAddress].
+func (a Address) String() string {
+	switch a.Network {
+	case networkTCP, "":
+		return a.Addr
+	case networkUnix:
+		return networkUnix + ":" + a.Addr
+	default:
+		return a.Network + "://" + a.Addr
+	}
+}
+
+func (a Address) apply(srv *Server) error {
+	srv.Addr = a.String()
+	return nil
+}
+
+// WithSocketMode sets the file mode of the unix domain sockets the [Server]
+// creates when it listens on an [Address] of network "unix". By default, the
+// mode is determined by the process' umask.
+func WithSocketMode(mode fs.FileMode) Option {
+	return optionFunc(func(srv *Server) error {
+		srv.socketMode = mode.Perm()
+		return nil
+	})
+}
+
+// listenUnix listens on the unix domain socket at path. A stale socket file,
+// which is left behind by a process that did not exit cleanly, is removed
+// first. The socket file is removed again when the returned listener is
+// closed.
+func listenUnix(path string, mode fs.FileMode) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	l, err := net.Listen(networkUnix, path)
+	if err != nil {
+		return nil, err
+	}
+	if mode != 0 {
+		if err = os.Chmod(path, mode); err != nil {
+			_ = l.Close()
+			return nil, errors.WithStack(err)
+		}
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(true)
+	return l, nil
+}
+
+// removeStaleSocket removes the socket file at path when no process accepts
+// connections on it. Any other kind of file is left untouched, in which case
+// listening on path fails.
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if err != nil || fi.Mode().Type() != fs.ModeSocket {
+		return nil
+	}
+
+	conn, err := net.DialTimeout(networkUnix, path, time.Second)
+	if err == nil {
+		// socket is in use by another process
+		_ = conn.Close()
+		return nil
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		// socket is likely in use by another process which is too busy
+		return nil
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// keepSocketFiles prevents the socket files of the [Server]'s unix domain
+// socket listeners from being removed when they are closed, because they are
+// taken over by another process.
+func (srv *Server) keepSocketFiles() {
+	srv.mut.RLock()
+	defer srv.mut.RUnlock()
+
+	for _, l := range srv.listeners {
+		if ul, ok := l.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+}
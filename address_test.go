@@ -0,0 +1,156 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleParseAddress() {
+	addr, err := ParseAddress("unix:/run/app.sock")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(addr.Network, addr.Addr)
+
+	// Output:
+	// unix /run/app.sock
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Address
+		wantErr error
+	}{
+		{input: "", want: Address{Network: "tcp"}},
+		{input: ":8080", want: Address{Network: "tcp", Addr: ":8080"}},
+		{input: "localhost:8080", want: Address{Network: "tcp", Addr: "localhost:8080"}},
+		{input: "tcp4://0.0.0.0:8080", want: Address{Network: "tcp4", Addr: "0.0.0.0:8080"}},
+		{input: "tcp6://[::1]:8080", want: Address{Network: "tcp6", Addr: "[::1]:8080"}},
+		{input: "unix:/run/app.sock", want: Address{Network: "unix", Addr: "/run/app.sock"}},
+		{input: "unix:///run/app.sock", want: Address{Network: "unix", Addr: "/run/app.sock"}},
+		{input: "unix:", wantErr: ErrInvalidAddress},
+		{input: "udp://:53", wantErr: ErrInvalidAddress},
+	}
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			have, err := ParseAddress(tc.input)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, have)
+
+			again, err := ParseAddress(have.String())
+			require.NoError(t, err)
+			assert.Equal(t, have, again)
+		})
+	}
+}
+
+func TestAddress_UnmarshalText(t *testing.T) {
+	var addr Address
+	require.NoError(t, addr.UnmarshalText([]byte("tcp4://:8080")))
+	assert.Equal(t, Address{Network: "tcp4", Addr: ":8080"}, addr)
+
+	text, err := addr.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "tcp4://:8080", string(text))
+
+	require.NoError(t, addr.Set(""))
+	assert.Equal(t, Address{Network: "tcp4", Addr: ":8080"}, addr)
+	assert.ErrorIs(t, addr.Set("foo://bar"), ErrInvalidAddress)
+}
+
+func TestPort_apply(t *testing.T) {
+	tests := map[string]string{
+		"":                ":8080",
+		"localhost":       "localhost:8080",
+		"localhost:80":    "localhost:8080",
+		"[::1]":           "[::1]:8080",
+		"tcp4://0.0.0.0":  "tcp4://0.0.0.0:8080",
+		"tcp6://[::1]:80": "tcp6://[::1]:8080",
+	}
+	for addr, want := range tests {
+		t.Run(addr, func(t *testing.T) {
+			srv := Server{Addr: addr}
+			require.NoError(t, Port(8080).apply(&srv))
+			assert.Equal(t, want, srv.Addr)
+		})
+	}
+	t.Run("unix", func(t *testing.T) {
+		srv := Server{Addr: "unix:/run/app.sock"}
+		assert.ErrorIs(t, Port(8080).apply(&srv), ErrInvalidAddress)
+	})
+}
+
+func TestServer_Run_unix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("socket file permissions are not supported on windows")
+	}
+
+	// use a short path, as the length of a socket path is limited
+	dir, err := os.MkdirTemp("", "serv")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	path := filepath.Join(dir, "app.sock")
+
+	// leave a stale socket file behind
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+	require.FileExists(t, path)
+
+	srv, err := New(
+		Address{Network: "unix", Addr: path},
+		WithSocketMode(0600),
+		WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = io.WriteString(w, "unix")
+		})),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "unix:"+path, srv.Addr)
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Run() }()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0600), fi.Mode().Perm())
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	_, err = io.WriteString(conn, "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	require.NoError(t, err)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	_ = conn.Close()
+	assert.Equal(t, "unix", string(body))
+
+	require.NoError(t, srv.Close())
+	assert.NoError(t, <-done)
+	assert.NoFileExists(t, path)
+}
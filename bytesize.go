@@ -0,0 +1,61 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-pogo/errors"
+)
+
+const ErrInvalidByteSize errors.Msg = "invalid byte size"
+
+var byteUnits = []struct {
+	name string
+	size uint64
+}{
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"GB", 1e9},
+	{"MB", 1e6},
+	{"kB", 1e3},
+	{"B", 1},
+}
+
+// parseByteSize parses a human-readable byte size, such as "10240", "16KiB"
+// or "1 MB". Units are case-insensitive, where KiB, MiB and GiB are powers of
+// 1024, and kB, MB and GB are powers of 1000.
+func parseByteSize(s string) (uint64, error) {
+	str := strings.TrimSpace(s)
+	unit := uint64(1)
+	for _, u := range byteUnits {
+		if n := len(str) - len(u.name); n >= 0 && strings.EqualFold(str[n:], u.name) {
+			str = strings.TrimSpace(str[:n])
+			unit = u.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseUint(str, 10, 64)
+	if err != nil || (unit > 1 && n > (1<<64-1)/unit) {
+		return 0, errors.Newf("%w %q", ErrInvalidByteSize, s)
+	}
+	return n * unit, nil
+}
+
+// formatByteSize formats n as a human-readable byte size using the largest
+// power of 1024 that exactly divides n, e.g. "10KiB".
+func formatByteSize(n uint64) string {
+	if n != 0 {
+		for _, u := range byteUnits[:3] {
+			if n%u.size == 0 {
+				return strconv.FormatUint(n/u.size, 10) + u.name
+			}
+		}
+	}
+	return strconv.FormatUint(n, 10)
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := map[string]uint64{
+		"0":       0,
+		"10240":   10240,
+		"512B":    512,
+		"16KiB":   16 << 10,
+		"16 kib":  16 << 10,
+		"2MiB":    2 << 20,
+		"1GiB":    1 << 30,
+		"1kB":     1000,
+		"3MB":     3e6,
+		" 2 GB  ": 2e9,
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			have, err := parseByteSize(input)
+			assert.NoError(t, err)
+			assert.Equal(t, want, have)
+		})
+	}
+
+	for _, input := range []string{"", "KiB", "-1", "1.5KiB", "10XB", "99999999999999GiB"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := parseByteSize(input)
+			assert.ErrorIs(t, err, ErrInvalidByteSize)
+		})
+	}
+}
+
+func TestFormatByteSize(t *testing.T) {
+	tests := map[uint64]string{
+		0:              "0",
+		1000:           "1000",
+		10240:          "10KiB",
+		3 << 20:        "3MiB",
+		5 << 30:        "5GiB",
+		math.MaxUint64: "18446744073709551615",
+	}
+	for input, want := range tests {
+		assert.Equal(t, want, formatByteSize(input))
+		have, err := parseByteSize(want)
+		assert.NoError(t, err)
+		assert.Equal(t, input, have)
+	}
+}
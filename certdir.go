@@ -0,0 +1,272 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-pogo/easytls"
+	"github.com/go-pogo/errors"
+)
+
+const ErrNoCertificates errors.Msg = "no certificates found"
+
+// certDirDefault is the base name of the pair which is used as default
+// certificate of a [CertDir].
+const certDirDefault = "default"
+
+var _ easytls.Option = (*CertDir)(nil)
+
+// CertDir loads all certificate and key pairs from a directory and serves
+// them using [tls.Config.GetCertificate], selecting the certificate based on
+// the server name (SNI) the client requests. A pair consists of a key file
+// with extension ".key" and a certificate file with the same base name and
+// extension ".crt" or ".pem", e.g. "example.com.key" and "example.com.crt".
+//
+// A certificate is selected when one of its DNS names or IP addresses
+// matches the requested server name. Wildcard DNS names, like
+// "*.example.com", match a single label. The pair with base name "default",
+// or else the first pair in lexical order, is used when no certificate
+// matches or the client does not send a server name.
+//
+// When a pair fails to (re)load, its last successfully loaded certificate
+// continues to be served.
+type CertDir struct {
+	dir   string
+	certs atomic.Pointer[certIndex]
+
+	mut     sync.Mutex
+	state   string
+	servers []*Server
+}
+
+type certIndex struct {
+	// pairs contains the loaded certificates by base name
+	pairs map[string]*tls.Certificate
+	// names contains the certificates by lowercase DNS name or IP address
+	names map[string]*tls.Certificate
+	def   *tls.Certificate
+}
+
+// NewCertDir creates a new [CertDir] and loads all pairs from dir. An error
+// is returned when any of the pairs cannot be loaded, or when dir does not
+// contain any pairs.
+func NewCertDir(dir string) (*CertDir, error) {
+	cd := &CertDir{dir: dir}
+	cd.state = cd.stat()
+	if err := cd.load(); err != nil {
+		return nil, err
+	}
+	return cd, nil
+}
+
+// WithCertDir sets [CertDir.GetCertificate] to the [Server]'s internal
+// [http.Server.TLSConfig]. It uses the value of [easytls.DefaultTLSConfig]
+// when the [Server] has no [tls.Config] yet. Reloads are logged using the
+// [Server]'s [CertLogger], if available.
+func WithCertDir(cd *CertDir) Option {
+	return optionFunc(func(srv *Server) error {
+		if srv.TLSConfig == nil {
+			srv.TLSConfig = easytls.DefaultTLSConfig()
+		}
+		_ = cd.ApplyTo(srv.TLSConfig, easytls.TargetServer)
+
+		cd.mut.Lock()
+		cd.servers = append(cd.servers, srv)
+		cd.mut.Unlock()
+		return nil
+	})
+}
+
+// ApplyTo sets [CertDir.GetCertificate] to the provided [tls.Config]. This
+// method implements the [easytls.Option] interface.
+func (cd *CertDir) ApplyTo(conf *tls.Config, _ easytls.Target) error {
+	if conf != nil {
+		conf.GetCertificate = cd.GetCertificate
+	}
+	return nil
+}
+
+// GetCertificate returns the certificate which matches the server name of
+// hello, or the default certificate. It can be used as
+// [tls.Config.GetCertificate].
+func (cd *CertDir) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	idx := cd.certs.Load()
+	if hello == nil || hello.ServerName == "" {
+		return idx.def, nil
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+	if cert, ok := idx.names[name]; ok {
+		return cert, nil
+	}
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		if cert, ok := idx.names["*"+name[i:]]; ok {
+			return cert, nil
+		}
+	}
+	return idx.def, nil
+}
+
+// Reload loads all pairs from the directory. Pairs which fail to load keep
+// their previously loaded certificate, if any.
+func (cd *CertDir) Reload() error {
+	cd.mut.Lock()
+	defer cd.mut.Unlock()
+
+	cd.state = cd.stat()
+	return cd.reload()
+}
+
+// ReloadOnChange blocks and checks the directory every interval for added,
+// removed or modified files, until ctx is done. All pairs are reloaded when
+// a change is detected. Failed reloads are logged and retried once the
+// directory changes again. An [ErrInvalidInterval] error is returned when
+// interval is not positive.
+func (cd *CertDir) ReloadOnChange(ctx context.Context, interval time.Duration) error {
+	return reloadOnChange(ctx, interval, func() {
+		cd.mut.Lock()
+		defer cd.mut.Unlock()
+
+		if state := cd.stat(); state != cd.state {
+			cd.state = state
+			_ = cd.reload()
+		}
+	})
+}
+
+// ReloadOnSignal blocks and calls [CertDir.Reload] each time one of the
+// provided signals is received, until ctx is done. It listens for
+// [syscall.SIGHUP] when no signals are provided.
+func (cd *CertDir) ReloadOnSignal(ctx context.Context, sig ...os.Signal) error {
+	return reloadOnSignal(ctx, cd.Reload, sig)
+}
+
+// stat returns a string describing the name, size and modification time of
+// all files in the directory, which changes when any of them changes.
+func (cd *CertDir) stat() string {
+	entries, err := os.ReadDir(cd.dir)
+	if err != nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, e := range entries {
+		// stat follows symlinks, which are commonly used when certificates
+		// are provided by e.g. Kubernetes secrets
+		fi, err := os.Stat(filepath.Join(cd.dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		sb.WriteString(e.Name())
+		sb.WriteByte('|')
+		sb.WriteString(strconv.FormatInt(fi.Size(), 10))
+		sb.WriteByte('|')
+		sb.WriteString(strconv.FormatInt(fi.ModTime().UnixNano(), 10))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// reload loads all pairs and logs the result. The [CertDir]'s lock must be
+// held when calling reload.
+func (cd *CertDir) reload() error {
+	err := cd.load()
+	logCertReload(cd.servers, cd.dir, err)
+	return err
+}
+
+func (cd *CertDir) load() error {
+	pairs, err := cd.pairs()
+	if err != nil {
+		return err
+	}
+
+	var prev map[string]*tls.Certificate
+	if idx := cd.certs.Load(); idx != nil {
+		prev = idx.pairs
+	}
+
+	idx := certIndex{
+		pairs: make(map[string]*tls.Certificate, len(pairs)),
+		names: make(map[string]*tls.Certificate),
+	}
+
+	bases := make([]string, 0, len(pairs))
+	for base := range pairs {
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+
+	for _, base := range bases {
+		cert, loadErr := loadKeyPair(pairs[base], filepath.Join(cd.dir, base+".key"))
+		if loadErr != nil {
+			err = errors.Append(err, errors.Wrapf(loadErr, "pair %q", base))
+			if cert = prev[base]; cert == nil {
+				continue
+			}
+		}
+
+		idx.pairs[base] = cert
+		if idx.def == nil || base == certDirDefault {
+			idx.def = cert
+		}
+		for _, name := range cert.Leaf.DNSNames {
+			name = strings.ToLower(name)
+			if _, exists := idx.names[name]; !exists {
+				idx.names[name] = cert
+			}
+		}
+		for _, ip := range cert.Leaf.IPAddresses {
+			if _, exists := idx.names[ip.String()]; !exists {
+				idx.names[ip.String()] = cert
+			}
+		}
+	}
+
+	if idx.def == nil {
+		return errors.Append(err, errors.Newf("%w in %q", ErrNoCertificates, cd.dir))
+	}
+	cd.certs.Store(&idx)
+	return err
+}
+
+// pairs returns the certificate file of each pair in the directory by its
+// base name.
+func (cd *CertDir) pairs() (map[string]string, error) {
+	entries, err := os.ReadDir(cd.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrLoadCertificate)
+	}
+
+	files := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		files[e.Name()] = struct{}{}
+	}
+
+	pairs := make(map[string]string)
+	for name := range files {
+		base, ok := strings.CutSuffix(name, ".key")
+		if !ok || base == "" {
+			continue
+		}
+		for _, ext := range []string{".crt", ".pem"} {
+			if _, ok = files[base+ext]; ok {
+				pairs[base] = filepath.Join(cd.dir, base+ext)
+				break
+			}
+		}
+	}
+	return pairs, nil
+}
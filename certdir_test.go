@@ -0,0 +1,104 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCertDir(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		_, err := NewCertDir(filepath.Join(t.TempDir(), "missing"))
+		assert.ErrorIs(t, err, ErrLoadCertificate)
+	})
+	t.Run("empty", func(t *testing.T) {
+		_, err := NewCertDir(t.TempDir())
+		assert.ErrorIs(t, err, ErrNoCertificates)
+	})
+}
+
+func TestCertDir(t *testing.T) {
+	dir := t.TempDir()
+	writePair := func(t *testing.T, base, cn, ext string) {
+		certFile, keyFile := writeTestCert(t, t.TempDir(), cn, time.Now().Add(time.Hour))
+		require.NoError(t, os.Rename(certFile, filepath.Join(dir, base+ext)))
+		require.NoError(t, os.Rename(keyFile, filepath.Join(dir, base+".key")))
+	}
+	get := func(t *testing.T, cd *CertDir, serverName string) string {
+		cert, err := cd.GetCertificate(&tls.ClientHelloInfo{ServerName: serverName})
+		require.NoError(t, err)
+		return cert.Leaf.Subject.CommonName
+	}
+
+	writePair(t, "a", "a.example.com", ".crt")
+	writePair(t, "wildcard", "*.example.com", ".pem")
+	writePair(t, "other", "example.org", ".crt")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("ignored"), 0600))
+
+	cd, err := NewCertDir(dir)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	srv, err := New(WithLogger(NewLogger(log.New(&buf, "", 0))), WithCertDir(cd))
+	require.NoError(t, err)
+	assert.True(t, ShouldUseTLS(srv.TLSConfig))
+
+	t.Run("sni", func(t *testing.T) {
+		assert.Equal(t, "a.example.com", get(t, cd, "a.example.com"))
+		assert.Equal(t, "a.example.com", get(t, cd, "A.Example.COM."))
+		assert.Equal(t, "*.example.com", get(t, cd, "b.example.com"))
+		assert.Equal(t, "example.org", get(t, cd, "example.org"))
+	})
+	t.Run("fallback", func(t *testing.T) {
+		// first pair in lexical order
+		assert.Equal(t, "a.example.com", get(t, cd, ""))
+		assert.Equal(t, "a.example.com", get(t, cd, "a.b.example.com"))
+		assert.Equal(t, "a.example.com", get(t, cd, "unknown.test"))
+	})
+	t.Run("default", func(t *testing.T) {
+		writePair(t, "default", "default.test", ".crt")
+		require.NoError(t, cd.Reload())
+		assert.Equal(t, "default.test", get(t, cd, "unknown.test"))
+		assert.Equal(t, "server reloaded certificate "+dir+"\n", buf.String())
+	})
+	t.Run("invalid", func(t *testing.T) {
+		buf.Reset()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "other.key"), []byte("invalid"), 0600))
+		writePair(t, "broken", "broken.test", ".crt")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.key"), []byte("invalid"), 0600))
+
+		assert.ErrorIs(t, cd.Reload(), ErrLoadCertificate)
+		assert.Contains(t, buf.String(), "server failed to reload certificate "+dir)
+		// last good certificate is kept
+		assert.Equal(t, "example.org", get(t, cd, "example.org"))
+		assert.Equal(t, "default.test", get(t, cd, "broken.test"))
+	})
+	t.Run("on change", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- cd.ReloadOnChange(ctx, 5*time.Millisecond) }()
+
+		writePair(t, "new", "new.test", ".crt")
+		assert.Eventually(t, func() bool {
+			return get(t, cd, "new.test") == "new.test"
+		}, time.Second, 5*time.Millisecond)
+
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+	t.Run("invalid interval", func(t *testing.T) {
+		assert.ErrorIs(t, cd.ReloadOnChange(context.Background(), -time.Second), ErrInvalidInterval)
+	})
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/go-pogo/easytls"
+	"github.com/go-pogo/errors"
+)
+
+const (
+	ErrLoadCertificate    errors.Msg = "unable to load certificate"
+	ErrCertificateExpired errors.Msg = "certificate has expired"
+	ErrInvalidInterval    errors.Msg = "interval must be positive"
+)
+
+// CertLogger is an optional interface a [Logger] can implement to log
+// certificate reloads performed by a [CertReloader] or [CertDir].
+type CertLogger interface {
+	LogServerCertReload(name, certFile string)
+	LogServerCertReloadError(name, certFile string, err error)
+}
+
+var _ easytls.TLSCertificateLoader = (*CertReloader)(nil)
+
+// CertReloader loads a certificate and key pair from files and serves it
+// using [tls.Config.GetCertificate]. It reloads the pair when the files
+// change, or when [CertReloader.Reload] is called. When the new pair is
+// invalid, the last successfully loaded certificate continues to be served.
+// Use [WithCertReloader] to serve its certificate from a [Server].
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+
+	mut      sync.Mutex
+	modTimes [2]time.Time
+	servers  []*Server
+}
+
+// NewCertReloader creates a new [CertReloader] and loads the certificate and
+// key pair from certFile and keyFile. An error is returned when the pair
+// cannot be loaded.
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	cr := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	cr.modTimes = cr.stat()
+	if err := cr.load(); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// WithCertReloader sets [CertReloader.GetCertificate] to the [Server]'s
+// internal [http.Server.TLSConfig]. It uses the value of
+// [easytls.DefaultTLSConfig] when the [Server] has no [tls.Config] yet.
+// Reloads are logged using the [Server]'s [CertLogger], if available.
+func WithCertReloader(cr *CertReloader) Option {
+	return optionFunc(func(srv *Server) error {
+		if srv.TLSConfig == nil {
+			srv.TLSConfig = easytls.DefaultTLSConfig()
+		}
+		srv.TLSConfig.GetCertificate = cr.GetCertificate
+
+		cr.mut.Lock()
+		cr.servers = append(cr.servers, srv)
+		cr.mut.Unlock()
+		return nil
+	})
+}
+
+// GetCertificate returns the last successfully loaded certificate. It can be
+// used as [tls.Config.GetCertificate].
+func (cr *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return cr.cert.Load(), nil
+}
+
+// LoadTLSCertificate returns the last successfully loaded certificate. This
+// method implements the [easytls.TLSCertificateLoader] interface.
+func (cr *CertReloader) LoadTLSCertificate() (*tls.Certificate, error) {
+	return cr.cert.Load(), nil
+}
+
+// Reload loads the certificate and key pair from its files. The previous
+// certificate remains in use when an error is returned.
+func (cr *CertReloader) Reload() error {
+	cr.mut.Lock()
+	defer cr.mut.Unlock()
+
+	cr.modTimes = cr.stat()
+	return cr.reload()
+}
+
+// ReloadOnChange blocks and checks the modification times of the certificate
+// and key files every interval, until ctx is done. The pair is reloaded when
+// either of them has changed. Failed reloads are logged and retried once the
+// files change again. An [ErrInvalidInterval] error is returned when interval
+// is not positive.
+func (cr *CertReloader) ReloadOnChange(ctx context.Context, interval time.Duration) error {
+	return reloadOnChange(ctx, interval, func() {
+		cr.mut.Lock()
+		defer cr.mut.Unlock()
+
+		if modTimes := cr.stat(); !modTimes[0].Equal(cr.modTimes[0]) || !modTimes[1].Equal(cr.modTimes[1]) {
+			cr.modTimes = modTimes
+			_ = cr.reload()
+		}
+	})
+}
+
+// ReloadOnSignal blocks and calls [CertReloader.Reload] each time one of the
+// provided signals is received, until ctx is done. It listens for
+// [syscall.SIGHUP] when no signals are provided.
+func (cr *CertReloader) ReloadOnSignal(ctx context.Context, sig ...os.Signal) error {
+	return reloadOnSignal(ctx, cr.Reload, sig)
+}
+
+// reloadOnChange blocks and calls check every interval, until ctx is done.
+// It is used by [CertReloader] and [CertDir] to poll for changed files.
+func reloadOnChange(ctx context.Context, interval time.Duration, check func()) error {
+	if interval <= 0 {
+		return errors.Newf("%w: %s", ErrInvalidInterval, interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			check()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// reloadOnSignal blocks and calls reload each time one of the signals is
+// received, until ctx is done. It listens for [syscall.SIGHUP] when sig is
+// empty.
+func reloadOnSignal(ctx context.Context, reload func() error, sig []os.Signal) error {
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig...)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ch:
+			// failures are logged
+			_ = reload()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// stat returns the modification times of the certificate and key files.
+// Files which cannot be accessed have a zero modification time.
+func (cr *CertReloader) stat() (res [2]time.Time) {
+	for i, file := range [2]string{cr.certFile, cr.keyFile} {
+		if fi, err := os.Stat(file); err == nil {
+			res[i] = fi.ModTime()
+		}
+	}
+	return res
+}
+
+// reload loads the pair and logs the result. The [CertReloader]'s lock must
+// be held when calling reload.
+func (cr *CertReloader) reload() error {
+	err := cr.load()
+	logCertReload(cr.servers, cr.certFile, err)
+	return err
+}
+
+func (cr *CertReloader) load() error {
+	cert, err := loadKeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return err
+	}
+
+	cr.cert.Store(cert)
+	return nil
+}
+
+// loadKeyPair loads a certificate and key pair from certFile and keyFile. An
+// error is returned when the pair is invalid or the certificate has expired.
+func loadKeyPair(certFile, keyFile string) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrLoadCertificate)
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, errors.Wrap(err, ErrLoadCertificate)
+		}
+	}
+	if time.Now().After(cert.Leaf.NotAfter) {
+		return nil, errors.Wrap(errors.New(ErrCertificateExpired), ErrLoadCertificate)
+	}
+	return &cert, nil
+}
+
+// logCertReload logs the result of reloading file using the [CertLogger] of
+// each of the servers, if available.
+func logCertReload(servers []*Server, file string, err error) {
+	for _, srv := range servers {
+		l, ok := srv.logger().(CertLogger)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			l.LogServerCertReloadError(srv.Name(), file, err)
+		} else {
+			l.LogServerCertReload(srv.Name(), file)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCert writes a self-signed certificate for cn, which expires at
+// notAfter, and its key to dir. It returns the paths of both files.
+func writeTestCert(t *testing.T, dir, cn string, notAfter time.Time) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestNewCertReloader(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		_, err := NewCertReloader("missing.pem", "missing.key")
+		assert.ErrorIs(t, err, ErrLoadCertificate)
+	})
+	t.Run("expired", func(t *testing.T) {
+		certFile, keyFile := writeTestCert(t, t.TempDir(), "localhost", time.Now().Add(-time.Minute))
+		_, err := NewCertReloader(certFile, keyFile)
+		assert.ErrorIs(t, err, ErrCertificateExpired)
+	})
+}
+
+func TestCertReloader(t *testing.T) {
+	commonName := func(t *testing.T, cr *CertReloader) string {
+		cert, err := cr.GetCertificate(nil)
+		require.NoError(t, err)
+		return cert.Leaf.Subject.CommonName
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, "first", time.Now().Add(time.Hour))
+	cr, err := NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, "first", commonName(t, cr))
+
+	var buf bytes.Buffer
+	srv, err := New(WithLogger(NewLogger(log.New(&buf, "", 0))), WithName("foo"), WithCertReloader(cr))
+	require.NoError(t, err)
+	require.NotNil(t, srv.TLSConfig.GetCertificate)
+	assert.True(t, ShouldUseTLS(srv.TLSConfig))
+
+	t.Run("reload", func(t *testing.T) {
+		buf.Reset()
+		writeTestCert(t, dir, "second", time.Now().Add(time.Hour))
+		require.NoError(t, cr.Reload())
+		assert.Equal(t, "second", commonName(t, cr))
+		assert.Equal(t, "server foo reloaded certificate "+certFile+"\n", buf.String())
+	})
+	t.Run("invalid", func(t *testing.T) {
+		buf.Reset()
+		require.NoError(t, os.WriteFile(keyFile, []byte("invalid"), 0600))
+		assert.ErrorIs(t, cr.Reload(), ErrLoadCertificate)
+		assert.Equal(t, "second", commonName(t, cr), "last good certificate should be kept")
+		assert.Contains(t, buf.String(), "server foo failed to reload certificate "+certFile)
+	})
+	t.Run("on change", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- cr.ReloadOnChange(ctx, 5*time.Millisecond) }()
+
+		writeTestCert(t, dir, "third", time.Now().Add(time.Hour))
+		// make sure the modification time changes on file systems with a
+		// low resolution
+		future := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(certFile, future, future))
+
+		assert.Eventually(t, func() bool {
+			return commonName(t, cr) == "third"
+		}, time.Second, 5*time.Millisecond)
+
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+	t.Run("invalid interval", func(t *testing.T) {
+		assert.ErrorIs(t, cr.ReloadOnChange(context.Background(), 0), ErrInvalidInterval)
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+
+	"github.com/go-pogo/easytls"
+)
+
+const schemeSPIFFE = "spiffe"
+
+// Identity is the identity of a client, as contained in its verified TLS
+// client certificate.
+type Identity struct {
+	// CommonName is the common name of the certificate's subject.
+	CommonName string
+	// DNSNames are the DNS name SANs of the certificate.
+	DNSNames []string
+	// EmailAddresses are the email address SANs of the certificate.
+	EmailAddresses []string
+	// URIs are the URI SANs of the certificate.
+	URIs []string
+	// SPIFFEID is the first URI SAN with the "spiffe" scheme, see
+	// https://spiffe.io.
+	SPIFFEID string
+}
+
+// IdentityFromCertificate returns the [Identity] contained in cert.
+func IdentityFromCertificate(cert *x509.Certificate) Identity {
+	id := Identity{
+		CommonName:     cert.Subject.CommonName,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+	}
+	for _, uri := range cert.URIs {
+		id.URIs = append(id.URIs, uri.String())
+		if id.SPIFFEID == "" && uri.Scheme == schemeSPIFFE {
+			id.SPIFFEID = uri.String()
+		}
+	}
+	return id
+}
+
+// IsZero indicates the [Identity] is empty, e.g. because the client is not
+// authenticated.
+func (id Identity) IsZero() bool {
+	return id.CommonName == "" && len(id.DNSNames) == 0 &&
+		len(id.EmailAddresses) == 0 && len(id.URIs) == 0
+}
+
+// Names returns all names of the [Identity]. These are its URIs, DNS names,
+// email addresses and common name, in that order.
+func (id Identity) Names() []string {
+	names := make([]string, 0, len(id.URIs)+len(id.DNSNames)+len(id.EmailAddresses)+1)
+	names = append(names, id.URIs...)
+	names = append(names, id.DNSNames...)
+	names = append(names, id.EmailAddresses...)
+	if id.CommonName != "" {
+		names = append(names, id.CommonName)
+	}
+	return names
+}
+
+// String returns the SPIFFE ID of the [Identity], or its common name when it
+// has no SPIFFE ID.
+func (id Identity) String() string {
+	if id.SPIFFEID != "" {
+		return id.SPIFFEID
+	}
+	return id.CommonName
+}
+
+// ClientIdentity gets the verified [Identity] of the client from the context
+// values. Its returned value is nil when the client is not authenticated
+// using a TLS client certificate.
+func ClientIdentity(ctx context.Context) *Identity {
+	if info := InfoFromContext(ctx); info != nil {
+		return info.ClientIdentity
+	}
+	return nil
+}
+
+// requestClientIdentity returns the [Identity] of the verified client
+// certificate of req, or nil when req does not have one.
+func requestClientIdentity(req *http.Request) *Identity {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	id := IdentityFromCertificate(req.TLS.VerifiedChains[0][0])
+	return &id
+}
+
+// WithClientAuth enables mutual TLS by setting the client authentication mode
+// and the pool of CAs which are used to verify client certificates, to the
+// [Server]'s internal [http.Server.TLSConfig]. It uses the value of
+// [easytls.DefaultTLSConfig] when the [Server] has no [tls.Config] yet.
+// The [Identity] of verified clients is available using [ClientIdentity] and
+// as [Info.ClientIdentity].
+func WithClientAuth(mode tls.ClientAuthType, clientCAs *x509.CertPool) Option {
+	return optionFunc(func(srv *Server) error {
+		if srv.TLSConfig == nil {
+			srv.TLSConfig = easytls.DefaultTLSConfig()
+		}
+		srv.TLSConfig.ClientAuth = mode
+		srv.TLSConfig.ClientCAs = clientCAs
+		return nil
+	})
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/go-pogo/easytls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityFromCertificate(t *testing.T) {
+	spiffe, _ := url.Parse("spiffe://example.org/ns/default/sa/billing")
+	other, _ := url.Parse("https://example.org/billing")
+
+	id := IdentityFromCertificate(&x509.Certificate{
+		Subject:        pkix.Name{CommonName: "billing"},
+		DNSNames:       []string{"billing.internal"},
+		EmailAddresses: []string{"billing@example.org"},
+		URIs:           []*url.URL{other, spiffe},
+	})
+	assert.Equal(t, Identity{
+		CommonName:     "billing",
+		DNSNames:       []string{"billing.internal"},
+		EmailAddresses: []string{"billing@example.org"},
+		URIs:           []string{other.String(), spiffe.String()},
+		SPIFFEID:       spiffe.String(),
+	}, id)
+	assert.False(t, id.IsZero())
+	assert.Equal(t, spiffe.String(), id.String())
+	assert.Equal(t, []string{
+		other.String(),
+		spiffe.String(),
+		"billing.internal",
+		"billing@example.org",
+		"billing",
+	}, id.Names())
+
+	assert.True(t, Identity{}.IsZero())
+	assert.Equal(t, "cn", Identity{CommonName: "cn"}.String())
+}
+
+func TestWithClientAuth(t *testing.T) {
+	ca, caKey, err := createCertificate(easytls.CACertificate(pkix.Name{CommonName: "test CA"}), nil, nil)
+	require.NoError(t, err)
+
+	spiffe, _ := url.Parse("spiffe://example.org/ns/default/sa/billing")
+	tmpl := easytls.ClientCertificate()
+	tmpl.Subject.CommonName = "billing"
+	tmpl.URIs = []*url.URL{spiffe}
+	clientCert, clientKey, err := createCertificate(tmpl, ca, caKey)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	srv, err := New(
+		WithSelfSignedTLS("127.0.0.1"),
+		WithClientAuth(tls.VerifyClientCertIfGiven, pool),
+		WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id := ClientIdentity(r.Context()); id != nil {
+				_, _ = io.WriteString(w, id.String())
+			}
+		})),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, srv.TLSConfig.ClientAuth)
+	srv.Addr = "127.0.0.1:0"
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Run() }()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	serverCA, err := x509.ParseCertificate(srv.TLSConfig.Certificates[0].Certificate[1])
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(serverCA)
+
+	get := func(t *testing.T, certs ...tls.Certificate) string {
+		client := http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs},
+		}}
+		defer client.CloseIdleConnections()
+
+		resp, err := client.Get("https://" + srv.ListenAddr().String())
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return string(body)
+	}
+
+	assert.Equal(t, spiffe.String(), get(t, tls.Certificate{
+		Certificate: [][]byte{clientCert.Raw},
+		PrivateKey:  clientKey,
+	}))
+	assert.Equal(t, "", get(t), "client is not authenticated")
+
+	require.NoError(t, srv.Close())
+	assert.NoError(t, <-done)
+}
@@ -5,12 +5,37 @@
 package serv
 
 import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"math"
 	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/go-pogo/errors"
 )
 
-var _ Option = (*Config)(nil)
+const ErrInvalidConfig errors.Msg = "invalid config"
+
+var (
+	_ Option           = (*Config)(nil)
+	_ json.Marshaler   = (*Config)(nil)
+	_ json.Unmarshaler = (*Config)(nil)
+)
 
+// Config contains the configurable values of a [Server]. Each field can be
+// loaded from an environment variable using [Config.LoadEnv], or from a
+// command-line flag using [Config.RegisterFlags]. Durations are parsed using
+// [time.ParseDuration], byte sizes accept units like "16KiB" or "1MB".
+// Config can be (un)marshaled to and from JSON, and formatted and parsed as
+// text using [Config.FormatText] and [Config.ParseText], with the snake cased
+// field names as keys, e.g. "read_timeout" and "max_header_bytes".
+// The default tags of the fields contain the values of [DefaultConfig].
 type Config struct {
 	// ReadTimeout is the maximum duration for reading the entire request,
 	// including the body.
@@ -30,11 +55,57 @@ type Config struct {
 	// ShutdownTimeout is the default maximum duration for shutting down the
 	// [Server] and waiting for all connections to be closed.
 	ShutdownTimeout time.Duration `default:"60s"`
+	// DrainDelay is the duration a [Server] keeps serving after a graceful
+	// shutdown is requested, before it actually shuts down. This gives load
+	// balancers time to stop sending new traffic, see [Server.Drain].
+	// There is no drain period when DrainDelay is zero.
+	DrainDelay time.Duration `default:"0s"`
 	// MaxHeaderBytes controls the maximum number of bytes the server will read
 	// parsing the [http.Request] header's keys and values, including the
 	// request line. It does not limit the size of the request body.
 	// See [http.Server.MaxHeaderBytes] for additional information.
 	MaxHeaderBytes uint64 `default:"10240"` // data.Bytes => 10 KiB
+	// MaxConns limits the number of concurrent connections. Hijacked
+	// connections are counted until the handler that hijacked them returns.
+	// There is no limit when MaxConns is zero.
+	MaxConns int `default:"0"`
+	// MaxConnsPerIP limits the number of concurrent connections per remote IP
+	// address. Connections over this limit are always rejected, regardless of
+	// LimitPolicy. There is no limit when MaxConnsPerIP is zero.
+	MaxConnsPerIP int `default:"0"`
+	// MaxAcceptRate limits the number of accepted connections per second.
+	// There is no limit when MaxAcceptRate is zero.
+	MaxAcceptRate int `default:"0"`
+	// LimitPolicy determines how connections over MaxConns or MaxAcceptRate
+	// are handled, see [LimitReject] and [LimitQueue].
+	LimitPolicy LimitPolicy `default:"reject"`
+	// Protocols is the set of protocols the [Server] accepts connections
+	// for, e.g. "http1,h2c" to serve HTTP/2 over unencrypted connections.
+	// The defaults of [http.Server] are used when Protocols is zero.
+	// Before Go 1.24, [Server.Run] returns an [ErrInvalidConfig] error when
+	// Protocols does not contain http1, contains h2c, or when any of the
+	// HTTP/2 settings below are set.
+	// See [http.Server.Protocols] for additional information.
+	Protocols Protocols `default:"default"`
+	// HTTP2MaxConcurrentStreams is the maximum number of concurrent streams
+	// per HTTP/2 connection. It requires Go 1.24 or later.
+	// See [http.HTTP2Config] for additional information.
+	HTTP2MaxConcurrentStreams int `default:"0"`
+	// HTTP2MaxReadFrameSize is the largest HTTP/2 frame the [Server] is
+	// willing to read, between 16KiB and 16MiB. It requires Go 1.24 or
+	// later.
+	// See [http.HTTP2Config] for additional information.
+	HTTP2MaxReadFrameSize uint64 `default:"0"`
+	// HTTP2SendPingTimeout is the duration after which a ping is sent when
+	// no frames are received on an HTTP/2 connection. It requires Go 1.24 or
+	// later.
+	// See [http.HTTP2Config] for additional information.
+	HTTP2SendPingTimeout time.Duration `default:"0s"`
+	// HTTP2PingTimeout is the duration after which an HTTP/2 connection is
+	// closed when no response to a ping is received. It requires Go 1.24 or
+	// later.
+	// See [http.HTTP2Config] for additional information.
+	HTTP2PingTimeout time.Duration `default:"0s"`
 }
 
 var defaultConfig = Config{
@@ -99,9 +170,375 @@ func (cfg *Config) ApplyTo(s *http.Server) {
 	if cfg.MaxHeaderBytes != 0 {
 		s.MaxHeaderBytes = int(cfg.MaxHeaderBytes)
 	}
+	cfg.applyProtocols(s)
 }
 
 func (cfg *Config) apply(srv *Server) error {
 	srv.Config = *cfg
 	return nil
 }
+
+// LoadEnv sets the fields of [Config] from the environment variables named
+// after the fields in upper snake case, prefixed with prefix and an
+// underscore. E.g. with prefix "HTTP", ReadTimeout is loaded from
+// HTTP_READ_TIMEOUT and MaxHeaderBytes from HTTP_MAX_HEADER_BYTES. Fields of
+// unset environment variables are left as is.
+func (cfg *Config) LoadEnv(prefix string) error {
+	return cfg.loadEnv(prefix, os.LookupEnv)
+}
+
+func (cfg *Config) loadEnv(prefix string, lookup func(string) (string, bool)) error {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+
+	var err error
+	for _, f := range cfg.fields() {
+		key := prefix + f.env
+		if v, ok := lookup(key); ok {
+			if e := f.value.Set(v); e != nil {
+				err = errors.Append(err, errors.Wrapf(e, "environment variable %s", key))
+			}
+		}
+	}
+	return errors.Wrap(err, ErrInvalidConfig)
+}
+
+// RegisterFlags defines a flag for each field of [Config] on fs. The flags
+// are named after the fields, in lowercase with dashes and prefixed with
+// prefix, e.g. "http-read-timeout" with prefix "http". The current values of
+// [Config] are used as the flags' default values.
+func (cfg *Config) RegisterFlags(fs *flag.FlagSet, prefix string) {
+	if prefix != "" && !strings.HasSuffix(prefix, "-") {
+		prefix += "-"
+	}
+
+	for _, f := range cfg.fields() {
+		name := strings.ToLower(strings.ReplaceAll(f.env, "_", "-"))
+		usage := "Server " + strings.ToLower(strings.ReplaceAll(f.env, "_", " "))
+		fs.Var(f.value, prefix+name, usage)
+	}
+}
+
+// Validate checks if the values of [Config] are consistent, and returns an
+// [ErrInvalidConfig] error containing all found issues otherwise.
+func (cfg *Config) Validate() error {
+	var err error
+	for _, f := range cfg.fields() {
+		var negative bool
+		switch v := f.value.(type) {
+		case *durationValue:
+			negative = *v < 0
+		case *intValue:
+			negative = *v < 0
+		}
+		if negative {
+			err = errors.Append(err, errors.Newf("%s must not be negative", f.name))
+		}
+	}
+	if cfg.ReadTimeout > 0 && cfg.ReadHeaderTimeout > cfg.ReadTimeout {
+		err = errors.Append(err, errors.Newf(
+			"ReadHeaderTimeout (%s) must not exceed ReadTimeout (%s)",
+			cfg.ReadHeaderTimeout, cfg.ReadTimeout,
+		))
+	}
+	if cfg.MaxHeaderBytes > math.MaxInt {
+		err = errors.Append(err, errors.Newf(
+			"MaxHeaderBytes (%s) must not exceed %s",
+			formatByteSize(cfg.MaxHeaderBytes), formatByteSize(math.MaxInt),
+		))
+	}
+	if n := cfg.HTTP2MaxReadFrameSize; n != 0 && (n < minHTTP2FrameSize || n > maxHTTP2FrameSize) {
+		err = errors.Append(err, errors.Newf(
+			"HTTP2MaxReadFrameSize (%s) must be between %s and %s",
+			formatByteSize(n), formatByteSize(minHTTP2FrameSize), formatByteSize(maxHTTP2FrameSize),
+		))
+	}
+	err = errors.Append(err, cfg.validateProtocols())
+	return errors.Wrap(err, ErrInvalidConfig)
+}
+
+// MarshalJSON encodes [Config] as a JSON object with human-readable duration
+// and byte size strings, e.g. {"read_timeout":"5s","max_header_bytes":"10KiB"}.
+// Counts, like MaxConns, are encoded as numbers.
+func (cfg Config) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range cfg.fields() {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(strconv.Quote(f.key()))
+		buf.WriteByte(':')
+		if _, ok := f.value.(*intValue); ok {
+			buf.WriteString(f.value.String())
+		} else {
+			buf.WriteString(strconv.Quote(f.value.String()))
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object into [Config]. Values may be strings,
+// which are parsed the same way as with [Config.LoadEnv], or numbers of
+// nanoseconds, bytes and counts. Just like with [Config.Default], missing
+// fields are set to their default values.
+func (cfg *Config) UnmarshalJSON(data []byte) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return errors.WithStack(err)
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var err error
+	values := make([]configValue, 0, len(obj))
+	for _, key := range keys {
+		raw := obj[key]
+		switch raw[0] {
+		case 'n': // null
+			continue
+		case '"':
+			var str string
+			if e := json.Unmarshal(raw, &str); e != nil {
+				err = errors.Append(err, errors.Wrapf(e, "key %s", key))
+				continue
+			}
+			values = append(values, configValue{key: key, val: str})
+		default:
+			values = append(values, configValue{key: key, val: string(raw), num: true})
+		}
+	}
+	return cfg.unmarshal(values, err)
+}
+
+// FormatText formats [Config] as lines of key = "value" pairs, which is
+// compatible with TOML, e.g.:
+//
+//	read_timeout = "5s"
+//	max_header_bytes = "10KiB"
+//
+// Config deliberately does not implement [encoding.TextMarshaler], so
+// decoders of formats like YAML and TOML handle it as a mapping of its
+// fields, instead of as a single scalar value.
+func (cfg Config) FormatText() []byte {
+	var buf bytes.Buffer
+	for _, f := range cfg.fields() {
+		buf.WriteString(f.key())
+		buf.WriteString(" = ")
+		buf.WriteString(strconv.Quote(f.value.String()))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// ParseText parses lines of key = value or key: value pairs, as formatted by
+// [Config.FormatText], into [Config]. Values may be quoted. Empty lines and
+// lines starting with # are ignored. Just like with [Config.Default],
+// missing fields are set to their default values.
+func (cfg *Config) ParseText(text []byte) error {
+	var err error
+	var values []configValue
+	for i, line := range strings.Split(string(text), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		n := strings.IndexAny(line, "=:")
+		if n < 0 {
+			err = errors.Append(err, errors.Newf("line %d: missing separator", i+1))
+			continue
+		}
+
+		key := strings.TrimSpace(line[:n])
+		val := strings.TrimSpace(line[n+1:])
+		if len(val) > 1 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+			val = val[1 : len(val)-1]
+		}
+		values = append(values, configValue{key: key, val: val})
+	}
+	return cfg.unmarshal(values, err)
+}
+
+// configValue is a value of a [Config] field, as decoded by
+// [Config.UnmarshalJSON] or [Config.ParseText]. When num is true, val is a
+// JSON number.
+type configValue struct {
+	key string
+	val string
+	num bool
+}
+
+// unmarshal sets values on a copy of [defaultConfig], which replaces [Config]
+// when there are no errors, including err. Keys are matched against the
+// fields of [Config], ignoring case, underscores and dashes. The values are
+// set in the order of the fields, the last value of a duplicate key wins, and
+// unknown keys are reported in the order of values. This keeps the order of
+// the returned errors deterministic.
+func (cfg *Config) unmarshal(values []configValue, err error) error {
+	byName := make(map[string]configValue, len(values))
+	for _, v := range values {
+		byName[v.name()] = v
+	}
+
+	res := defaultConfig
+	for _, f := range res.fields() {
+		name := strings.ToLower(f.name)
+		v, ok := byName[name]
+		if !ok {
+			continue
+		}
+
+		delete(byName, name)
+		if e := v.set(f); e != nil {
+			err = errors.Append(err, errors.Wrapf(e, "key %s", v.key))
+		}
+	}
+	for _, v := range values {
+		if _, ok := byName[v.name()]; ok {
+			delete(byName, v.name())
+			err = errors.Append(err, errors.Newf("unknown key %s", v.key))
+		}
+	}
+	if err != nil {
+		return errors.Wrap(err, ErrInvalidConfig)
+	}
+
+	*cfg = res
+	return nil
+}
+
+// name returns the key of v in lowercase, without any underscores and
+// dashes, so it can be matched against the name of a [configField].
+func (v configValue) name() string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(v.key))
+}
+
+// set sets v as the value of field f. JSON numbers of durations are
+// nanoseconds.
+func (v configValue) set(f configField) error {
+	if d, ok := f.value.(*durationValue); ok && v.num {
+		n, err := strconv.ParseInt(v.val, 10, 64)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		*d = durationValue(n)
+		return nil
+	}
+	return f.value.Set(v.val)
+}
+
+type configField struct {
+	name  string
+	env   string
+	value flag.Value
+}
+
+// key returns the name of the field as used when (un)marshaling [Config].
+func (f configField) key() string { return strings.ToLower(f.env) }
+
+// fields returns a [configField] for each field of [Config] with a default
+// tag. Its env name is derived from the field's name using [envName] and its
+// value is a [flag.Value] which points to the field.
+func (cfg *Config) fields() []configField {
+	val := reflect.ValueOf(cfg).Elem()
+	typ := val.Type()
+
+	res := make([]configField, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if _, ok := field.Tag.Lookup("default"); !ok {
+			continue
+		}
+
+		var v flag.Value
+		switch ptr := val.Field(i).Addr().Interface().(type) {
+		case *time.Duration:
+			v = (*durationValue)(ptr)
+		case *uint64:
+			v = (*byteSizeValue)(ptr)
+		case *int:
+			v = (*intValue)(ptr)
+		case flag.Value:
+			v = ptr
+		default:
+			panic("serv: unsupported Config field type " + field.Type.String())
+		}
+
+		res = append(res, configField{
+			name:  field.Name,
+			env:   envName(field.Name),
+			value: v,
+		})
+	}
+	return res
+}
+
+// envName converts the name of a [Config] field to upper snake case, e.g.
+// "MaxConnsPerIP" to "MAX_CONNS_PER_IP" and "HTTP2PingTimeout" to
+// "HTTP2_PING_TIMEOUT". A new word starts at an uppercase letter which
+// follows a lowercase letter, or which is followed by a lowercase letter.
+func envName(name string) string {
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if i != 0 && isUpper(c) && (isLower(name[i-1]) ||
+			(i+1 < len(name) && isLower(name[i+1]))) {
+			sb.WriteByte('_')
+		}
+		if isLower(c) {
+			c -= 'a' - 'A'
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+func isUpper(c byte) bool { return c >= 'A' && c <= 'Z' }
+
+func isLower(c byte) bool { return c >= 'a' && c <= 'z' }
+
+type durationValue time.Duration
+
+func (d *durationValue) Set(s string) error {
+	v, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	*d = durationValue(v)
+	return nil
+}
+
+func (d *durationValue) String() string { return time.Duration(*d).String() }
+
+type byteSizeValue uint64
+
+func (b *byteSizeValue) Set(s string) error {
+	v, err := parseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*b = byteSizeValue(v)
+	return nil
+}
+
+func (b *byteSizeValue) String() string { return formatByteSize(uint64(*b)) }
+
+type intValue int
+
+func (i *intValue) Set(s string) error {
+	v, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	*i = intValue(v)
+	return nil
+}
+
+func (i *intValue) String() string { return strconv.Itoa(int(*i)) }
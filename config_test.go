@@ -5,11 +5,18 @@
 package serv
 
 import (
+	"encoding"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-pogo/errors"
 	"github.com/go-pogo/rawconv"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -84,3 +91,263 @@ func TestConfig_ApplyTo(t *testing.T) {
 		}, &have)
 	})
 }
+
+func TestConfig_LoadEnv(t *testing.T) {
+	t.Run("prefix", func(t *testing.T) {
+		env := map[string]string{
+			"HTTP_READ_TIMEOUT":     "3s",
+			"HTTP_MAX_HEADER_BYTES": "16KiB",
+			"READ_HEADER_TIMEOUT":   "1s",
+		}
+		lookup := func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		}
+
+		cfg := defaultConfig
+		require.NoError(t, cfg.loadEnv("HTTP", lookup))
+
+		want := defaultConfig
+		want.ReadTimeout = 3 * time.Second
+		want.MaxHeaderBytes = 16 << 10
+		assert.Equal(t, want, cfg)
+	})
+	t.Run("os", func(t *testing.T) {
+		t.Setenv("SERV_WRITE_TIMEOUT", "1m")
+
+		var cfg Config
+		require.NoError(t, cfg.LoadEnv("SERV_"))
+		assert.Equal(t, Config{WriteTimeout: time.Minute}, cfg)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		lookup := func(key string) (string, bool) {
+			return "foo", key == "IDLE_TIMEOUT" || key == "MAX_HEADER_BYTES"
+		}
+
+		var cfg Config
+		err := cfg.loadEnv("", lookup)
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		assert.ErrorIs(t, err, ErrInvalidByteSize)
+		assert.Contains(t, fmt.Sprintf("%+v", err), "IDLE_TIMEOUT")
+	})
+}
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"ReadTimeout":               "READ_TIMEOUT",
+		"MaxConnsPerIP":             "MAX_CONNS_PER_IP",
+		"HTTP2MaxConcurrentStreams": "HTTP2_MAX_CONCURRENT_STREAMS",
+		"HTTP2PingTimeout":          "HTTP2_PING_TIMEOUT",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, envName(name), name)
+	}
+
+	t.Run("all fields", func(t *testing.T) {
+		var cfg Config
+		assert.Len(t, cfg.fields(), reflect.TypeOf(cfg).NumField(), "each field should have a default tag")
+	})
+}
+
+func TestConfig_RegisterFlags(t *testing.T) {
+	cfg := defaultConfig
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.RegisterFlags(fs, "http")
+
+	assert.Equal(t, "10KiB", fs.Lookup("http-max-header-bytes").DefValue)
+	assert.Equal(t, "2s", fs.Lookup("http-read-header-timeout").DefValue)
+
+	require.NoError(t, fs.Parse([]string{
+		"-http-idle-timeout=1m30s",
+		"-http-max-header-bytes", "1MB",
+	}))
+
+	want := defaultConfig
+	want.IdleTimeout = 90 * time.Second
+	want.MaxHeaderBytes = 1e6
+	assert.Equal(t, want, cfg)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"zero":    {},
+		"default": {cfg: defaultConfig},
+		"only read header timeout": {
+			cfg: Config{ReadHeaderTimeout: time.Second},
+		},
+		"read header timeout exceeds read timeout": {
+			cfg:     Config{ReadTimeout: time.Second, ReadHeaderTimeout: 2 * time.Second},
+			wantErr: true,
+		},
+		"negative": {
+			cfg:     Config{ShutdownTimeout: -time.Second},
+			wantErr: true,
+		},
+		"negative max conns": {
+			cfg:     Config{MaxConnsPerIP: -1},
+			wantErr: true,
+		},
+		"max header bytes": {
+			cfg:     Config{MaxHeaderBytes: math.MaxUint64},
+			wantErr: true,
+		},
+		"http2 max read frame size too small": {
+			cfg:     Config{HTTP2MaxReadFrameSize: 1024},
+			wantErr: true,
+		},
+		"http2 max read frame size too large": {
+			cfg:     Config{HTTP2MaxReadFrameSize: 1 << 24},
+			wantErr: true,
+		},
+		"negative http2 max concurrent streams": {
+			cfg:     Config{HTTP2MaxConcurrentStreams: -1},
+			wantErr: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidConfig)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_JSON(t *testing.T) {
+	t.Run("marshal", func(t *testing.T) {
+		have, err := json.Marshal(defaultConfig)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"read_timeout": "5s",
+			"read_header_timeout": "2s",
+			"write_timeout": "10s",
+			"idle_timeout": "2m0s",
+			"shutdown_timeout": "1m0s",
+			"drain_delay": "0s",
+			"max_header_bytes": "10KiB",
+			"max_conns": 0,
+			"max_conns_per_ip": 0,
+			"max_accept_rate": 0,
+			"limit_policy": "reject",
+			"protocols": "default",
+			"http2_max_concurrent_streams": 0,
+			"http2_max_read_frame_size": "0",
+			"http2_send_ping_timeout": "0s",
+			"http2_ping_timeout": "0s"
+		}`, string(have))
+
+		var cfg Config
+		require.NoError(t, json.Unmarshal(have, &cfg))
+		assert.Equal(t, defaultConfig, cfg)
+	})
+	t.Run("unmarshal", func(t *testing.T) {
+		var cfg Config
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"read_timeout": "3s",
+			"WriteTimeout": 1000000000,
+			"idle_timeout": null,
+			"max_header_bytes": 2048,
+			"max_conns": 100,
+			"limit_policy": "queue",
+			"protocols": "http1,h2c",
+			"http2_max_read_frame_size": "1MiB"
+		}`), &cfg))
+
+		want := defaultConfig
+		want.ReadTimeout = 3 * time.Second
+		want.WriteTimeout = time.Second
+		want.MaxHeaderBytes = 2048
+		want.MaxConns = 100
+		want.LimitPolicy = LimitQueue
+		want.Protocols = ProtocolHTTP1 | ProtocolUnencryptedHTTP2
+		want.HTTP2MaxReadFrameSize = 1 << 20
+		assert.Equal(t, want, cfg)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		cfg := Config{ReadTimeout: time.Second}
+		err := json.Unmarshal([]byte(`{"read_timeout":"foo","unknown":"1s"}`), &cfg)
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		assert.Equal(t, Config{ReadTimeout: time.Second}, cfg, "should not be changed")
+	})
+}
+
+func TestConfig_FormatText(t *testing.T) {
+	t.Run("not a scalar", func(t *testing.T) {
+		var cfg any = new(Config)
+		_, ok := cfg.(encoding.TextMarshaler)
+		assert.False(t, ok, "should not implement encoding.TextMarshaler")
+		_, ok = cfg.(encoding.TextUnmarshaler)
+		assert.False(t, ok, "should not implement encoding.TextUnmarshaler")
+	})
+	t.Run("format", func(t *testing.T) {
+		cfg := defaultConfig
+		cfg.MaxHeaderBytes = 1 << 20
+		cfg.LimitPolicy = LimitQueue
+		cfg.Protocols = ProtocolHTTP1 | ProtocolHTTP2
+		cfg.HTTP2MaxConcurrentStreams = 100
+		cfg.HTTP2PingTimeout = 15 * time.Second
+
+		have := cfg.FormatText()
+		assert.Equal(t, `read_timeout = "5s"
+read_header_timeout = "2s"
+write_timeout = "10s"
+idle_timeout = "2m0s"
+shutdown_timeout = "1m0s"
+drain_delay = "0s"
+max_header_bytes = "1MiB"
+max_conns = "0"
+max_conns_per_ip = "0"
+max_accept_rate = "0"
+limit_policy = "queue"
+protocols = "http1,http2"
+http2_max_concurrent_streams = "100"
+http2_max_read_frame_size = "0"
+http2_send_ping_timeout = "0s"
+http2_ping_timeout = "15s"
+`, string(have))
+
+		var res Config
+		require.NoError(t, res.ParseText(have))
+		assert.Equal(t, cfg, res)
+	})
+	t.Run("parse", func(t *testing.T) {
+		var cfg Config
+		require.NoError(t, cfg.ParseText([]byte(`
+# http server
+read-timeout: 30s
+shutdown_timeout = '5s'
+MAX_HEADER_BYTES = 16KiB
+`)))
+
+		want := defaultConfig
+		want.ReadTimeout = 30 * time.Second
+		want.ShutdownTimeout = 5 * time.Second
+		want.MaxHeaderBytes = 16 << 10
+		assert.Equal(t, want, cfg)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var cfg Config
+		assert.ErrorIs(t, cfg.ParseText([]byte("read_timeout")), ErrInvalidConfig)
+		assert.ErrorIs(t, cfg.ParseText([]byte("foo = 1s")), ErrInvalidConfig)
+		assert.ErrorIs(t, cfg.ParseText([]byte("idle_timeout = 1")), ErrInvalidConfig)
+		assert.True(t, cfg.IsZero())
+	})
+	t.Run("error order", func(t *testing.T) {
+		var cfg Config
+		err := cfg.ParseText([]byte("foo = 1\nwrite_timeout = x\nread_timeout = y\nbar = 2"))
+
+		var multi errors.MultiError
+		require.ErrorAs(t, err, &multi)
+		have := make([]string, 0, len(multi.Unwrap()))
+		for _, e := range multi.Unwrap() {
+			have = append(have, strings.SplitN(e.Error(), ":", 2)[0])
+		}
+		assert.Equal(t, []string{"key read_timeout", "key write_timeout", "unknown key foo", "unknown key bar"}, have)
+	})
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// connStats keeps track of the connections and requests of a [Server] using
+// atomic counters, see [Server.Stats].
+type connStats struct {
+	// open is the number of open connections
+	open atomic.Int64
+	// states is the number of open connections per [http.ConnState]
+	states   [http.StateIdle + 1]atomic.Int64
+	accepted atomic.Uint64
+	hijacked atomic.Uint64
+	requests atomic.Int64
+	// conns contains the last [http.ConnState] of each open connection
+	conns sync.Map
+	// hijackedConns contains the hijacked connections of which the handler
+	// is still running
+	hijackedConns  sync.Map
+	hijackedActive atomic.Int64
+}
+
+func (cs *connStats) reset() {
+	cs.open.Store(0)
+	for i := range cs.states {
+		cs.states[i].Store(0)
+	}
+	cs.conns.Clear()
+}
+
+// track updates the counters when conn changes to state. Each connection
+// only changes state from a single goroutine at a time, and is stored under
+// its own key, so this does not cause any lock contention.
+func (cs *connStats) track(conn net.Conn, state http.ConnState) {
+	var prev any
+	var ok bool
+
+	switch state {
+	case http.StateNew:
+		cs.open.Add(1)
+		cs.accepted.Add(1)
+		fallthrough
+	case http.StateActive, http.StateIdle:
+		prev, ok = cs.conns.Swap(conn, state)
+		cs.states[state].Add(1)
+
+	case http.StateHijacked, http.StateClosed:
+		if state == http.StateHijacked {
+			cs.hijacked.Add(1)
+			cs.hijackedActive.Add(1)
+			cs.hijackedConns.Store(conn, struct{}{})
+		}
+		cs.open.Add(-1)
+		prev, ok = cs.conns.LoadAndDelete(conn)
+	}
+	if ok {
+		cs.states[prev.(http.ConnState)].Add(-1)
+	}
+}
+
+// untrackHijacked stops tracking conn when it is a hijacked connection, and
+// reports whether it was.
+func (cs *connStats) untrackHijacked(conn net.Conn) bool {
+	if _, ok := cs.hijackedConns.LoadAndDelete(conn); ok {
+		cs.hijackedActive.Add(-1)
+		return true
+	}
+	return false
+}
+
+// trackConns wraps the internal [http.Server.ConnState] with a function that
+// keeps track of the connections of the [Server]. Any [http.Server.ConnState]
+// set by the user is still called. The [Server]'s lock must be held when
+// calling trackConns.
+func (srv *Server) trackConns() {
+	if srv.connStateWrapped {
+		// restore the user's ConnState, which may have been copied by
+		// resetServer
+		srv.ConnState = srv.connState
+	}
+
+	srv.stats.reset()
+	srv.connState = srv.ConnState
+	srv.connStateWrapped = true
+
+	next := srv.connState
+	srv.ConnState = func(conn net.Conn, state http.ConnState) {
+		srv.stats.track(conn, state)
+		if state == http.StateClosed {
+			srv.limiter.release(conn)
+		}
+		if next != nil {
+			next(conn, state)
+		}
+	}
+}
+
+// wrapConnContext wraps the internal [http.Server.ConnContext] with a function
+// that adds values of the connection to its context:
+//   - a [connValue] with the connection and the channel that is closed once
+//     the [Server] begins to shut down, see [ShuttingDown]. The connection is
+//     used to stop tracking it when it is hijacked;
+//   - the connection from a trusted proxy, so its [ProxyHeader] can be
+//     retrieved using [ProxyHeaderFromContext].
+//
+// Any [http.Server.ConnContext] set by the user is still called. The
+// [Server]'s lock must be held when calling wrapConnContext.
+func (srv *Server) wrapConnContext() {
+	if srv.connContextWrapped {
+		// restore the user's ConnContext, which may have been copied by
+		// resetServer
+		srv.ConnContext = srv.connContext
+	}
+
+	srv.connContext = srv.ConnContext
+	srv.connContextWrapped = true
+
+	next := srv.connContext
+	shuttingDown := srv.shuttingDown
+	srv.ConnContext = func(ctx context.Context, conn net.Conn) context.Context {
+		ctx = context.WithValue(ctx, ctxConnKey{}, &connValue{
+			conn:         conn,
+			shuttingDown: shuttingDown,
+		})
+		if pc := findProxyConn(conn); pc != nil {
+			ctx = context.WithValue(ctx, ctxProxyConnKey{}, pc)
+		}
+		if next != nil {
+			ctx = next(ctx, conn)
+		}
+		return ctx
+	}
+}
+
+// waitConnsClosed blocks until all tracked connections are closed or
+// hijacked, and thus no longer access the internal [http.Server].
+func (srv *Server) waitConnsClosed() {
+	if srv.stats.open.Load() <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(5 * time.Millisecond)
+	defer ticker.Stop()
+	for range ticker.C {
+		if srv.stats.open.Load() <= 0 {
+			return
+		}
+	}
+}
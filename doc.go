@@ -10,7 +10,29 @@ package, with a focus on security, flexibility and ease of use.
 
 Included features:
 - [Server] with sane and safe defaults;
+- load [Config] from environment variables and/or flags;
+- structured logging with [log/slog] using [NewSlogLogger];
+- update [Config] without restarting using [Server.UpdateConfig];
 - [Server] [State] retrieval;
+- live connection and request statistics using [Server.Stats];
+- connection limits per server, per IP and per second;
+- select HTTP/1, HTTP/2 and unencrypted HTTP/2 (h2c) [Protocols] and tune HTTP/2 using [Config];
+- [State] change subscriptions and lifecycle [Hook]s;
+- end long-lived and hijacked requests on shutdown using [ShuttingDown];
+- log errors, bound listeners and shutdown outcomes using [LifecycleLogger];
+- drain period before shutdown for load balancers using [Config.DrainDelay] and [Server.HealthHandler];
+- pause and resume handling new requests using [Server.Pause] and [Server.Resume];
+- restart without closing listeners using [Server.Restart];
+- manage multiple servers as a single unit using [Group];
+- serve on multiple addresses and/or listeners using [Endpoint];
+- listen on unix domain sockets and IPv4 or IPv6 only addresses using [Address];
+- PROXY protocol v1 and v2 from trusted proxies using [WithProxyProtocol];
+- reload TLS certificates from disk without restarting using [CertReloader];
+- serve multiple TLS certificates from a directory based on SNI using [CertDir];
+- self-signed TLS for local development using [WithSelfSignedTLS];
+- mutual TLS with client identities using [WithClientAuth];
+- systemd socket activation using [WithInheritedListener];
+- zero-downtime binary upgrades using [Upgrader];
 - [Router]/[ServeMux] with easy (mass) [Route] registration;
 - Set custom "not found" http.Handler on [ServeMux];
 - support for access logging.
@@ -0,0 +1,138 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-pogo/errors"
+)
+
+const ErrUnableToDrain errors.Msg = "unable to drain server"
+
+const stateDraining = "draining"
+
+// Drain starts the drain period of the [Server], which precedes a graceful
+// shutdown. While draining, the [Server] keeps serving requests but adds a
+// "Connection: close" header to each response, so clients do not reuse
+// their connections, and reports it is no longer ready using
+// [Server.Draining] and [Server.HealthHandler]. This gives load balancers the
+// time to stop sending new traffic to the [Server], before it is shut down.
+// Drain blocks for [Config.DrainDelay], or until ctx is done, in which case
+// ctx's error is returned. It returns immediately when [Config.DrainDelay] is
+// zero or the [Server] is already draining.
+// [Server.Shutdown] and [Server.RunContext] call Drain before shutting down,
+// unless the [Server] is already draining. The drain period is not limited by
+// [Config.ShutdownTimeout], but is cut short when the context passed to
+// [Server.Shutdown] would otherwise not leave enough time for the graceful
+// shutdown that follows.
+// An [InvalidStateError] containing a [ErrUnableToDrain] error is returned
+// when the [Server] is not started or paused.
+func (srv *Server) Drain(ctx context.Context) error {
+	srv.mut.RLock()
+	state, delay := srv.state, srv.Config.DrainDelay
+	srv.mut.RUnlock()
+
+	if !state.isRunning() {
+		return errors.WithStack(&InvalidStateError{
+			Err:   ErrUnableToDrain,
+			State: state,
+		})
+	}
+	if !srv.draining.CompareAndSwap(false, true) || delay == 0 {
+		return nil
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	}
+}
+
+// drainBeforeShutdown calls [Server.Drain] before a graceful shutdown. When
+// ctx has a deadline, the drain period is cut short so the shutdown keeps a
+// budget of [Config.ShutdownTimeout], or half of the remaining time when that
+// is less or not set. An error is only returned when ctx is done during the
+// drain period, any other drain errors are also reported by the shutdown.
+func (srv *Server) drainBeforeShutdown(ctx context.Context) error {
+	drainCtx := ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		srv.mut.RLock()
+		budget := srv.Config.ShutdownTimeout
+		srv.mut.RUnlock()
+
+		if half := time.Until(deadline) / 2; budget == 0 || budget > half {
+			budget = half
+		}
+
+		var cancelFn context.CancelFunc
+		drainCtx, cancelFn = context.WithDeadline(ctx, deadline.Add(-budget))
+		defer cancelFn()
+	}
+
+	err := srv.Drain(drainCtx)
+	if err == nil || errors.Is(err, ErrUnableToDrain) || ctx.Err() == nil {
+		return nil
+	}
+	return errors.Wrap(err, ErrUnableToDrain)
+}
+
+// Draining indicates the [Server] is in its drain period, see [Server.Drain].
+func (srv *Server) Draining() bool { return srv.draining.Load() }
+
+// HealthHandler returns an [http.Handler] that can be used as readiness
+// check by load balancers and orchestrators. It responds with an HTTP 200 "ok"
+// status when the [Server] is started and not draining, and with an HTTP 503
+// "service unavailable" status otherwise. The body of the latter response
+// contains the reason, e.g. "draining" or the [State] of the [Server], like
+// "paused".
+// Requests for the returned handler are still handled while the [Server] is
+// paused, when it is the [Server]'s handler or is registered directly, without
+// a name or middleware, on an [http.ServeMux] or [ServeMux] that is the
+// [Server]'s handler.
+func (srv *Server) HealthHandler() http.Handler { return &healthHandler{srv} }
+
+type healthHandler struct{ srv *Server }
+
+func (h *healthHandler) ServeHTTP(wri http.ResponseWriter, _ *http.Request) {
+	wri.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	wri.Header().Set("Cache-Control", "no-store")
+
+	var reason string
+	if state := h.srv.State(); state != StateStarted {
+		reason = state.String()
+	} else if h.srv.Draining() {
+		reason = stateDraining
+	} else {
+		_, _ = wri.Write([]byte("ok"))
+		return
+	}
+
+	wri.WriteHeader(http.StatusServiceUnavailable)
+	_, _ = wri.Write([]byte(reason))
+}
+
+// handlerLookup is implemented by [http.ServeMux] and [ServeMux].
+type handlerLookup interface {
+	Handler(req *http.Request) (h http.Handler, pattern string)
+}
+
+// isHealthHandler indicates req is handled by a handler returned from
+// [Server.HealthHandler], either directly by h or by the handler h looks up
+// for req.
+func isHealthHandler(h http.Handler, req *http.Request) bool {
+	if l, ok := h.(handlerLookup); ok {
+		h, _ = l.Handler(req)
+	}
+	_, ok := h.(*healthHandler)
+	return ok
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Drain(t *testing.T) {
+	t.Run("not started", func(t *testing.T) {
+		var srv Server
+		assert.ErrorIs(t, srv.Drain(context.Background()), ErrUnableToDrain)
+		assert.False(t, srv.Draining())
+	})
+
+	srv, err := New(&Config{DrainDelay: 200 * time.Millisecond})
+	require.NoError(t, err)
+	srv.Handler = srv.HealthHandler()
+	srv.Addr = "127.0.0.1:0"
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Run() }()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	get := func(t *testing.T) (*http.Response, string) {
+		resp, err := http.Get("http://" + srv.ListenAddr().String())
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return resp, string(body)
+	}
+
+	resp, body := get(t)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ok", body)
+	assert.False(t, resp.Close)
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- srv.Shutdown(context.Background()) }()
+	require.Eventually(t, srv.Draining, time.Second, time.Millisecond)
+
+	resp, body = get(t)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "draining", body)
+	assert.True(t, resp.Close, "response should have Connection: close header")
+	assert.Equal(t, StateStarted, srv.State(), "should keep serving while draining")
+
+	assert.NoError(t, <-shutdown)
+	assert.NoError(t, <-done)
+	assert.Equal(t, StateClosed, srv.State())
+}
+
+func TestServer_Shutdown_drainBudget(t *testing.T) {
+	srv, err := New(&Config{DrainDelay: time.Minute})
+	require.NoError(t, err)
+	srv.Addr = "127.0.0.1:0"
+
+	handling := make(chan struct{})
+	srv.Handler = http.HandlerFunc(func(wri http.ResponseWriter, _ *http.Request) {
+		close(handling)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = wri.Write([]byte("done"))
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Run() }()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	resp := make(chan string, 1)
+	go func() {
+		r, err := http.Get("http://" + srv.ListenAddr().String())
+		if err != nil {
+			resp <- err.Error()
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		resp <- string(body)
+	}()
+	<-handling
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), time.Second)
+	defer cancelFn()
+
+	start := time.Now()
+	assert.NoError(t, srv.Shutdown(ctx), "graceful shutdown should keep its own budget")
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, "done", <-resp)
+	assert.NoError(t, <-done)
+	assert.Equal(t, StateClosed, srv.State())
+}
+
+func TestServer_HealthHandler(t *testing.T) {
+	var srv Server
+	rec := httptest.NewRecorder()
+	srv.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "unstarted", rec.Body.String())
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-pogo/errors"
+)
+
+// ServerError labels an error of a [Server] within a [Group] with the
+// [Server]'s name.
+type ServerError struct {
+	// Name of the [Server], see [Server.Name].
+	Name string
+	// Err is the error returned by the [Server].
+	Err error
+}
+
+func (e *ServerError) Unwrap() error { return e.Err }
+
+func (e *ServerError) Error() string {
+	if e.Name == "" {
+		return "server: " + e.Err.Error()
+	}
+	return "server " + e.Name + ": " + e.Err.Error()
+}
+
+func serverErr(srv *Server, err error) error {
+	if err == nil {
+		return nil
+	}
+	return errors.WithStack(&ServerError{
+		Name: srv.Name(),
+		Err:  err,
+	})
+}
+
+// Group manages multiple [Server]s as a single unit. It starts all of its
+// [Server]s concurrently, and shuts all of them down when one of them fails.
+type Group struct {
+	servers []*Server
+}
+
+// NewGroup creates a new [Group] containing the provided [Server]s.
+func NewGroup(servers ...*Server) *Group {
+	g := Group{servers: make([]*Server, 0, len(servers))}
+	for _, srv := range servers {
+		if srv != nil {
+			g.servers = append(g.servers, srv)
+		}
+	}
+	return &g
+}
+
+// Servers returns the [Server]s within the [Group].
+func (g *Group) Servers() []*Server { return g.servers }
+
+// State returns the aggregate [State] of all [Server]s within the [Group].
+// It is [StateErrored] when any of the [Server]s has errored, and
+// [StateClosing] when any of them is closing, or when some [Server]s are
+// started while others have already closed. Otherwise, it is [StatePaused]
+// when any of the [Server]s is paused, [StateStarted] when any of them is
+// started, [StateClosed] when any of them is closed, or [StateUnstarted].
+func (g *Group) State() State {
+	var has [StatePaused + 1]bool
+	for _, srv := range g.servers {
+		if state := srv.State(); int(state) < len(has) {
+			has[state] = true
+		}
+	}
+
+	switch {
+	case has[StateErrored]:
+		return StateErrored
+	case has[StateClosing]:
+		return StateClosing
+	case has[StatePaused]:
+		if has[StateClosed] {
+			return StateClosing
+		}
+		return StatePaused
+	case has[StateStarted]:
+		if has[StateClosed] {
+			return StateClosing
+		}
+		return StateStarted
+	case has[StateClosed]:
+		return StateClosed
+	default:
+		return StateUnstarted
+	}
+}
+
+// Run starts all [Server]s within the [Group] concurrently using
+// [Server.Run]. When one of the [Server]s fails to start or returns an error,
+// all other [Server]s are shut down using [Server.Shutdown]. Run blocks until
+// all [Server]s have stopped and returns all errors, each labeled with its
+// [Server]'s name using [ServerError].
+func (g *Group) Run() error { return g.RunContext(context.Background()) }
+
+// RunContext is similar to [Group.Run], but also shuts down all [Server]s when
+// ctx is done, the same way as [Server.RunContext] does.
+func (g *Group) RunContext(ctx context.Context) error {
+	for i, srv := range g.servers {
+		if err := srv.start(); err != nil {
+			err = serverErr(srv, err)
+			// shut down any server that has already started
+			for _, started := range g.servers[:i] {
+				err = errors.Append(err, serverErr(started, started.Shutdown(context.Background())))
+			}
+			return err
+		}
+	}
+
+	errs := make(chan error, len(g.servers))
+	for _, srv := range g.servers {
+		go func() { errs <- serverErr(srv, srv.run()) }()
+	}
+
+	var err error
+	var stopped bool
+	stop := func(ctx context.Context, force bool) {
+		if !stopped {
+			stopped = true
+			err = errors.Append(err, g.shutdown(ctx, force))
+		}
+	}
+
+	done := ctx.Done()
+	for n := 0; n < len(g.servers); {
+		select {
+		case e := <-errs:
+			n++
+			if e != nil {
+				err = errors.Append(err, e)
+				stop(context.Background(), false)
+			}
+
+		case <-done:
+			done = nil
+			stop(context.WithoutCancel(ctx), true)
+		}
+	}
+	return err
+}
+
+// Shutdown gracefully shuts down all started [Server]s within the [Group] in
+// parallel, using [Server.Shutdown], regardless of the [State] of the other
+// [Server]s. It returns all errors, each labeled with its [Server]'s name
+// using [ServerError].
+// An [InvalidStateError] containing a [ErrUnableToShutdown] error is returned
+// when none of the [Server]s is started.
+func (g *Group) Shutdown(ctx context.Context) error {
+	if state := g.State(); !g.hasRunning() {
+		return errors.WithStack(&InvalidStateError{
+			Err:   ErrUnableToShutdown,
+			State: state,
+		})
+	}
+	return g.shutdown(ctx, false)
+}
+
+func (g *Group) shutdown(ctx context.Context, force bool) error {
+	return g.each(func(srv *Server) error {
+		err := srv.shutdownRestarted(ctx, force)
+		if errors.Is(err, ErrUnableToShutdown) {
+			// server is not started or already stopped
+			return nil
+		}
+		return err
+	})
+}
+
+// Close immediately closes all started [Server]s within the [Group] in
+// parallel, using [Server.Close], regardless of the [State] of the other
+// [Server]s. It returns all errors, each labeled with its [Server]'s name
+// using [ServerError].
+// An [InvalidStateError] containing a [ErrUnableToClose] error is returned
+// when none of the [Server]s is started.
+func (g *Group) Close() error {
+	if state := g.State(); !g.hasRunning() {
+		return errors.WithStack(&InvalidStateError{
+			Err:   ErrUnableToClose,
+			State: state,
+		})
+	}
+
+	return g.each(func(srv *Server) error {
+		err := srv.Close()
+		if errors.Is(err, ErrUnableToClose) {
+			return nil
+		}
+		return err
+	})
+}
+
+// WaitReady blocks until all [Server]s within the [Group] are ready to accept
+// incoming connections, or ctx is done. See [Server.WaitReady] for
+// additional information.
+func (g *Group) WaitReady(ctx context.Context) error {
+	var err error
+	for _, srv := range g.servers {
+		err = errors.Append(err, serverErr(srv, srv.WaitReady(ctx)))
+	}
+	return err
+}
+
+// hasRunning indicates at least one of the [Server]s within the [Group] is
+// running, closing or restarting, and can thus be shut down or closed.
+func (g *Group) hasRunning() bool {
+	for _, srv := range g.servers {
+		if state := srv.State(); state.isRunning() || state == StateClosing {
+			return true
+		}
+	}
+	return false
+}
+
+// each calls fn for each [Server] within the [Group] in parallel, and returns
+// all errors, each labeled with its [Server]'s name.
+func (g *Group) each(fn func(srv *Server) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(g.servers))
+	for i, srv := range g.servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = serverErr(srv, fn(srv))
+		}()
+	}
+	wg.Wait()
+
+	var err error
+	for _, e := range errs {
+		err = errors.Append(err, e)
+	}
+	return err
+}
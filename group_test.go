@@ -0,0 +1,150 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerError(t *testing.T) {
+	err := &ServerError{Name: "api", Err: ErrUnableToStart}
+	assert.Equal(t, "server api: "+ErrUnableToStart.Error(), err.Error())
+	assert.ErrorIs(t, err, ErrUnableToStart)
+
+	err.Name = ""
+	assert.Equal(t, "server: "+ErrUnableToStart.Error(), err.Error())
+}
+
+func TestGroup_State(t *testing.T) {
+	tests := map[string]struct {
+		states []State
+		want   State
+	}{
+		"empty":     {want: StateUnstarted},
+		"unstarted": {states: []State{StateUnstarted, StateUnstarted}, want: StateUnstarted},
+		"started":   {states: []State{StateStarted, StateStarted}, want: StateStarted},
+		"starting":  {states: []State{StateUnstarted, StateStarted}, want: StateStarted},
+		"closing":   {states: []State{StateStarted, StateClosing}, want: StateClosing},
+		"degraded":  {states: []State{StateStarted, StateClosed}, want: StateClosing},
+		"closed":    {states: []State{StateClosed, StateClosed}, want: StateClosed},
+		"errored":   {states: []State{StateStarted, StateErrored}, want: StateErrored},
+		"paused":    {states: []State{StateStarted, StatePaused}, want: StatePaused},
+		"paused closed": {
+			states: []State{StatePaused, StateClosed},
+			want:   StateClosing,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			servers := make([]*Server, 0, len(tc.states))
+			for _, state := range tc.states {
+				servers = append(servers, &Server{state: state})
+			}
+			assert.Equal(t, tc.want, NewGroup(servers...).State())
+		})
+	}
+}
+
+func TestGroup_Run(t *testing.T) {
+	t.Run("shutdown", func(t *testing.T) {
+		api, err := New(WithName("api"))
+		require.NoError(t, err)
+		api.Addr = "127.0.0.1:0"
+		admin, err := New(WithName("admin"))
+		require.NoError(t, err)
+		admin.Addr = "127.0.0.1:0"
+
+		g := NewGroup(api, admin)
+		done := make(chan error, 1)
+		go func() { done <- g.Run() }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, g.WaitReady(ctx))
+		assert.Equal(t, StateStarted, g.State())
+
+		assert.NoError(t, g.Shutdown(context.Background()))
+		assert.NoError(t, <-done)
+		assert.Equal(t, StateClosed, g.State())
+		assert.ErrorIs(t, g.Shutdown(context.Background()), ErrUnableToShutdown)
+	})
+	t.Run("error", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer l.Close()
+
+		api, err := New(WithName("api"))
+		require.NoError(t, err)
+		api.Addr = "127.0.0.1:0"
+		admin, err := New(WithName("admin"))
+		require.NoError(t, err)
+		admin.Addr = l.Addr().String()
+
+		g := NewGroup(api, admin)
+		err = g.Run()
+		assert.ErrorIs(t, err, ErrListen)
+
+		var se *ServerError
+		require.ErrorAs(t, err, &se)
+		assert.Equal(t, "admin", se.Name)
+		assert.Equal(t, StateErrored, admin.State())
+		assert.Equal(t, StateClosed, api.State())
+	})
+	t.Run("context", func(t *testing.T) {
+		api := &Server{Addr: "127.0.0.1:0"}
+		admin := &Server{Addr: "127.0.0.1:0"}
+		g := NewGroup(api, admin)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- g.RunContext(ctx) }()
+		require.NoError(t, g.WaitReady(context.Background()))
+
+		cancel()
+		assert.NoError(t, <-done)
+		assert.Equal(t, StateClosed, g.State())
+	})
+}
+
+func TestGroup_Shutdown(t *testing.T) {
+	t.Run("not started", func(t *testing.T) {
+		g := NewGroup(&Server{}, &Server{state: StateErrored})
+		assert.ErrorIs(t, g.Shutdown(context.Background()), ErrUnableToShutdown)
+		assert.ErrorIs(t, g.Close(), ErrUnableToClose)
+	})
+
+	stop := map[string]func(g *Group) error{
+		"shutdown": func(g *Group) error { return g.Shutdown(context.Background()) },
+		"close":    (*Group).Close,
+	}
+	for name, stopFn := range stop {
+		t.Run(name+" with errored server", func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer l.Close()
+
+			errored := &Server{Addr: l.Addr().String()}
+			assert.ErrorIs(t, errored.Run(), ErrListen)
+
+			healthy := &Server{Addr: "127.0.0.1:0"}
+			done := make(chan error, 1)
+			go func() { done <- healthy.Run() }()
+			require.NoError(t, healthy.WaitReady(context.Background()))
+
+			g := NewGroup(errored, healthy)
+			require.Equal(t, StateErrored, g.State())
+			assert.NoError(t, stopFn(g))
+			assert.NoError(t, <-done)
+			assert.Equal(t, StateClosed, healthy.State())
+			assert.Equal(t, StateErrored, errored.State())
+		})
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pogo/errors"
+)
+
+const ErrHookFailed errors.Msg = "lifecycle hook failed"
+
+// StateEvent describes a transition from one [State] of a [Server] to
+// another.
+type StateEvent struct {
+	// Name of the [Server], see [WithName].
+	Name string
+	// From is the [State] before the transition.
+	From State
+	// To is the [State] after the transition.
+	To State
+	// Time of the transition.
+	Time time.Time
+	// Duration the [Server] has been in the From [State].
+	Duration time.Duration
+	// Err is the error that caused the transition, if any. It is only set
+	// when To is [StateErrored].
+	Err error
+}
+
+// HookFunc is called during a [Server]'s lifecycle with the [StateEvent] that
+// triggered it.
+type HookFunc func(ctx context.Context, event StateEvent) error
+
+var _ Option = (*Hook)(nil)
+
+// Hook is an [Option] which registers a [HookFunc] that is called when the
+// [Server] transitions to a specific [State]. Use [OnStart], [OnShutdown] or
+// [OnClosed] to create a [Hook].
+type Hook struct {
+	// On is the [State] that triggers the hook.
+	On State
+	// Fn is the [HookFunc] that is called.
+	Fn HookFunc
+	// Timeout optionally limits the duration of a single call to Fn.
+	Timeout time.Duration
+}
+
+// OnStart returns a [Hook] which is called when the [Server] has started and
+// its listeners are bound, but before it starts accepting connections. An
+// error returned by fn prevents the [Server] from serving and is returned by
+// [Server.Run] (or one of the other serve methods).
+func OnStart(fn HookFunc) Hook { return Hook{On: StateStarted, Fn: fn} }
+
+// OnShutdown returns a [Hook] which is called when the [Server] is about to
+// shut down or close, before any listeners are closed. An error returned by
+// fn is returned by [Server.Shutdown] or [Server.Close].
+func OnShutdown(fn HookFunc) Hook { return Hook{On: StateClosing, Fn: fn} }
+
+// OnClosed returns a [Hook] which is called after the [Server] is shut down
+// or closed completely. An error returned by fn is returned by
+// [Server.Shutdown] or [Server.Close].
+func OnClosed(fn HookFunc) Hook { return Hook{On: StateClosed, Fn: fn} }
+
+// WithTimeout returns a copy of the [Hook] with its Timeout set to d.
+func (h Hook) WithTimeout(d time.Duration) Hook {
+	h.Timeout = d
+	return h
+}
+
+func (h Hook) apply(srv *Server) error {
+	if h.Fn != nil {
+		srv.hooks = append(srv.hooks, h)
+	}
+	return nil
+}
+
+func (h Hook) call(ctx context.Context, event StateEvent) error {
+	if h.Timeout > 0 {
+		var cancelFn context.CancelFunc
+		ctx, cancelFn = context.WithTimeout(ctx, h.Timeout)
+		defer cancelFn()
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- h.Fn(ctx, event) }()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runHooks calls all [Hook]s that are registered for the [State] of the last
+// [StateEvent], and returns any of their errors wrapped with [ErrHookFailed].
+func (srv *Server) runHooks(ctx context.Context, state State) error {
+	srv.mut.RLock()
+	hooks := srv.hooks
+	event := srv.event
+	srv.mut.RUnlock()
+
+	var err error
+	for _, h := range hooks {
+		if h.On != state {
+			continue
+		}
+		if e := h.call(ctx, event); e != nil {
+			err = errors.Append(err, errors.Wrap(e, ErrHookFailed))
+		}
+	}
+	return err
+}
+
+type subscriber struct {
+	ch chan StateEvent
+	fn func(StateEvent)
+}
+
+// Subscribe returns a channel which receives a [StateEvent] for each [State]
+// transition of the [Server], and a function to unsubscribe. The channel is
+// buffered with size. Events are dropped when the channel's buffer is full,
+// so a slow receiver never blocks the [Server]. The channel is closed when
+// unsubscribing.
+func (srv *Server) Subscribe(size int) (<-chan StateEvent, func()) {
+	ch := make(chan StateEvent, size)
+	unsub := srv.subscribe(&subscriber{ch: ch})
+	return ch, func() {
+		if unsub() {
+			close(ch)
+		}
+	}
+}
+
+// OnStateChange registers fn to be called for each [State] transition of the
+// [Server]. It returns a function to unregister fn. Calls to fn happen
+// synchronously, outside the [Server]'s lock, so fn may safely call methods
+// like [Server.State].
+func (srv *Server) OnStateChange(fn func(StateEvent)) func() {
+	unsub := srv.subscribe(&subscriber{fn: fn})
+	return func() { unsub() }
+}
+
+func (srv *Server) subscribe(sub *subscriber) func() bool {
+	srv.subMut.Lock()
+	srv.subs = append(srv.subs, sub)
+	srv.subMut.Unlock()
+
+	return func() bool {
+		srv.subMut.Lock()
+		defer srv.subMut.Unlock()
+		for i, s := range srv.subs {
+			if s == sub {
+				srv.subs = append(srv.subs[:i:i], srv.subs[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// setState changes the [Server]'s [State] and returns the resulting
+// [StateEvent] and true, or false when the [State] did not change. The
+// [Server]'s lock must be held when calling setState. Use emit to notify any
+// subscribers after the lock is released.
+func (srv *Server) setState(state State, err error) (StateEvent, bool) {
+	if srv.state == state {
+		return StateEvent{}, false
+	}
+
+	now := time.Now()
+	event := StateEvent{
+		Name: srv.name,
+		From: srv.state,
+		To:   state,
+		Time: now,
+	}
+	if !srv.event.Time.IsZero() {
+		event.Duration = now.Sub(srv.event.Time)
+	}
+	if state == StateErrored {
+		event.Err = err
+	}
+
+	srv.state = state
+	srv.event = event
+	return event, true
+}
+
+// emit notifies all subscribers of event when ok is true.
+func (srv *Server) emit(event StateEvent, ok bool) {
+	if !ok {
+		return
+	}
+
+	// hold the lock while sending to channels, so they cannot be closed by
+	// unsubscribing in the meantime
+	srv.subMut.Lock()
+	subs := make([]*subscriber, len(srv.subs))
+	copy(subs, srv.subs)
+	for _, sub := range subs {
+		if sub.ch == nil {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+	srv.subMut.Unlock()
+
+	for _, sub := range subs {
+		if sub.fn != nil {
+			sub.fn(event)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Subscribe(t *testing.T) {
+	srv, err := New(WithName("foo"))
+	require.NoError(t, err)
+
+	ch, unsub := srv.Subscribe(10)
+
+	var have []State
+	unregister := srv.OnStateChange(func(ev StateEvent) {
+		assert.Equal(t, ev.To, srv.State())
+		have = append(have, ev.To)
+	})
+
+	require.NoError(t, srv.start())
+	require.NoError(t, srv.Shutdown(context.Background()))
+	unregister()
+	require.NoError(t, srv.start())
+	unsub()
+
+	want := []State{StateStarted, StateClosing, StateClosed}
+	assert.Equal(t, want, have)
+
+	var events []StateEvent
+	for ev := range ch {
+		events = append(events, ev)
+	}
+	require.Len(t, events, 4)
+	assert.Equal(t, StateUnstarted, events[0].From)
+	assert.Equal(t, "foo", events[0].Name)
+	for i, ev := range events {
+		if i > 0 {
+			assert.Equal(t, events[i-1].To, ev.From)
+			assert.False(t, ev.Time.Before(events[i-1].Time))
+		}
+	}
+	assert.Equal(t, StateStarted, events[3].To)
+}
+
+func TestHook(t *testing.T) {
+	errHook := errors.New("hook error")
+
+	t.Run("shutdown", func(t *testing.T) {
+		var have []State
+		record := func(err error) HookFunc {
+			return func(_ context.Context, ev StateEvent) error {
+				have = append(have, ev.To)
+				return err
+			}
+		}
+
+		srv, err := New(
+			OnShutdown(record(nil)),
+			OnShutdown(record(errHook)),
+			OnClosed(record(nil)),
+		)
+		require.NoError(t, err)
+		require.NoError(t, srv.start())
+
+		err = srv.Shutdown(context.Background())
+		assert.ErrorIs(t, err, ErrHookFailed)
+		assert.ErrorIs(t, err, errHook)
+		assert.Equal(t, []State{StateClosing, StateClosing, StateClosed}, have)
+		assert.Equal(t, StateClosed, srv.State())
+	})
+	t.Run("timeout", func(t *testing.T) {
+		srv, err := New(OnClosed(func(ctx context.Context, _ StateEvent) error {
+			<-ctx.Done()
+			return nil
+		}).WithTimeout(10 * time.Millisecond))
+		require.NoError(t, err)
+		require.NoError(t, srv.start())
+		assert.ErrorIs(t, srv.Close(), context.DeadlineExceeded)
+	})
+	t.Run("start error", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		srv, err := New(OnStart(func(context.Context, StateEvent) error {
+			return errHook
+		}))
+		require.NoError(t, err)
+		assert.ErrorIs(t, srv.Serve(l), errHook)
+		assert.Equal(t, StateErrored, srv.State())
+
+		// listener should be closed
+		_, err = l.Accept()
+		assert.ErrorIs(t, err, net.ErrClosed)
+	})
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.24
+
+package serv
+
+import "net/http"
+
+// applyProtocols sets [Config.Protocols] and the HTTP/2 settings of [Config]
+// to s. A non-nil [http.Server.HTTP2] is copied before it is modified.
+func (cfg *Config) applyProtocols(s *http.Server) {
+	if cfg.Protocols != 0 {
+		var p http.Protocols
+		p.SetHTTP1(cfg.Protocols.Has(ProtocolHTTP1))
+		p.SetHTTP2(cfg.Protocols.Has(ProtocolHTTP2))
+		p.SetUnencryptedHTTP2(cfg.Protocols.Has(ProtocolUnencryptedHTTP2))
+		s.Protocols = &p
+	}
+
+	if cfg.HTTP2MaxConcurrentStreams == 0 && cfg.HTTP2MaxReadFrameSize == 0 &&
+		cfg.HTTP2SendPingTimeout == 0 && cfg.HTTP2PingTimeout == 0 {
+		return
+	}
+
+	var conf http.HTTP2Config
+	if s.HTTP2 != nil {
+		conf = *s.HTTP2
+	}
+	if cfg.HTTP2MaxConcurrentStreams != 0 {
+		conf.MaxConcurrentStreams = cfg.HTTP2MaxConcurrentStreams
+	}
+	if cfg.HTTP2MaxReadFrameSize != 0 {
+		conf.MaxReadFrameSize = int(cfg.HTTP2MaxReadFrameSize)
+	}
+	if cfg.HTTP2SendPingTimeout != 0 {
+		conf.SendPingTimeout = cfg.HTTP2SendPingTimeout
+	}
+	if cfg.HTTP2PingTimeout != 0 {
+		conf.PingTimeout = cfg.HTTP2PingTimeout
+	}
+	s.HTTP2 = &conf
+}
+
+// validateProtocols does not report any issues, all protocols and HTTP/2
+// settings of [Config] are supported by [http.Server].
+func (cfg *Config) validateProtocols() error { return nil }
@@ -0,0 +1,48 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !go1.24
+
+package serv
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"github.com/go-pogo/errors"
+)
+
+// applyProtocols disables HTTP/2 over TLS by setting an empty, non-nil
+// [http.Server.TLSNextProto] when [Config.Protocols] only contains
+// [ProtocolHTTP1]. Other protocols and the HTTP/2 settings of [Config] are
+// not supported by [http.Server] before Go 1.24, see
+// [Config.validateProtocols].
+func (cfg *Config) applyProtocols(s *http.Server) {
+	if cfg.Protocols == ProtocolHTTP1 && s.TLSNextProto == nil {
+		s.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	}
+}
+
+// validateProtocols reports the protocols and HTTP/2 settings of [Config]
+// which are not supported by [http.Server] before Go 1.24.
+func (cfg *Config) validateProtocols() error {
+	var err error
+	if cfg.Protocols != 0 && !cfg.Protocols.Has(ProtocolHTTP1) {
+		err = errors.Append(err, errors.Newf(
+			"Protocols (%s) must contain %s before Go 1.24",
+			cfg.Protocols, ProtocolHTTP1,
+		))
+	}
+	if cfg.Protocols.Has(ProtocolUnencryptedHTTP2) {
+		err = errors.Append(err, errors.Newf(
+			"Protocols (%s) must not contain %s before Go 1.24",
+			cfg.Protocols, ProtocolUnencryptedHTTP2,
+		))
+	}
+	if cfg.HTTP2MaxConcurrentStreams != 0 || cfg.HTTP2MaxReadFrameSize != 0 ||
+		cfg.HTTP2SendPingTimeout != 0 || cfg.HTTP2PingTimeout != 0 {
+		err = errors.Append(err, errors.Newf("HTTP/2 settings are not supported before Go 1.24"))
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !go1.24
+
+package serv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_validateProtocols(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"zero":       {},
+		"http1 only": {cfg: Config{Protocols: ProtocolHTTP1}},
+		"http2 only": {
+			cfg:     Config{Protocols: ProtocolHTTP2},
+			wantErr: true,
+		},
+		"h2c": {
+			cfg:     Config{Protocols: ProtocolHTTP1 | ProtocolUnencryptedHTTP2},
+			wantErr: true,
+		},
+		"http2 settings": {
+			cfg:     Config{HTTP2PingTimeout: time.Second},
+			wantErr: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tc.wantErr {
+				assert.Error(t, tc.cfg.validateProtocols())
+			} else {
+				assert.NoError(t, tc.cfg.validateProtocols())
+			}
+		})
+	}
+}
+
+func TestServer_Run_unsupportedProtocols(t *testing.T) {
+	srv, err := New(&Config{Protocols: ProtocolHTTP1 | ProtocolUnencryptedHTTP2})
+	assert.NoError(t, err)
+	srv.Addr = "127.0.0.1:0"
+
+	assert.ErrorIs(t, srv.Run(), ErrInvalidConfig)
+	assert.Equal(t, StateUnstarted, srv.State())
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.24
+
+package serv
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_applyProtocols(t *testing.T) {
+	t.Run("zero", func(t *testing.T) {
+		var have http.Server
+		(&Config{}).applyProtocols(&have)
+		assert.Nil(t, have.Protocols)
+		assert.Nil(t, have.HTTP2)
+	})
+	t.Run("non-zero", func(t *testing.T) {
+		orig := &http.HTTP2Config{MaxConcurrentStreams: 10, WriteByteTimeout: time.Second}
+		have := http.Server{HTTP2: orig}
+
+		cfg := Config{
+			Protocols:             ProtocolHTTP1 | ProtocolUnencryptedHTTP2,
+			HTTP2MaxReadFrameSize: 1 << 20,
+			HTTP2SendPingTimeout:  5 * time.Second,
+			HTTP2PingTimeout:      2 * time.Second,
+		}
+		cfg.applyProtocols(&have)
+
+		require.NotNil(t, have.Protocols)
+		assert.True(t, have.Protocols.HTTP1())
+		assert.False(t, have.Protocols.HTTP2())
+		assert.True(t, have.Protocols.UnencryptedHTTP2())
+
+		assert.Equal(t, &http.HTTP2Config{
+			MaxConcurrentStreams: 10,
+			MaxReadFrameSize:     1 << 20,
+			SendPingTimeout:      5 * time.Second,
+			PingTimeout:          2 * time.Second,
+			WriteByteTimeout:     time.Second,
+		}, have.HTTP2)
+		assert.Equal(t, 0, orig.MaxReadFrameSize, "should not be changed")
+	})
+}
+
+func TestServer_Run_unencryptedHTTP2(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Protocols = ProtocolHTTP1 | ProtocolUnencryptedHTTP2
+	cfg.HTTP2MaxConcurrentStreams = 50
+
+	srv, err := New(cfg, WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	})))
+	require.NoError(t, err)
+	srv.Addr = "127.0.0.1:0"
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Run() }()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	client := http.Client{Transport: &http.Transport{Protocols: &protocols}}
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Get("http://" + srv.ListenAddr().String())
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, "HTTP/2.0", string(body))
+
+	require.NoError(t, srv.Close())
+	assert.NoError(t, <-done)
+}
@@ -16,6 +16,10 @@ type Info struct {
 	ServerName  string
 	HandlerName string
 	RequestID   string
+	// ClientIdentity is the [Identity] of a client which is authenticated
+	// using a verified TLS client certificate, see [WithClientAuth]. It is nil
+	// when the client is not authenticated.
+	ClientIdentity *Identity
 }
 
 // ContextWithInfo adds an Info value to the context. It returns a derived
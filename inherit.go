@@ -0,0 +1,214 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-pogo/errors"
+)
+
+const (
+	ErrInheritListeners errors.Msg = "unable to inherit listeners"
+
+	envListenPID     = "LISTEN_PID"
+	envListenFDs     = "LISTEN_FDS"
+	envListenFDNames = "LISTEN_FDNAMES"
+	envUpgradePPID   = "SERV_UPGRADE_PPID"
+
+	// listenFDsStart is the first file descriptor passed using the socket
+	// activation protocol, see sd_listen_fds(3).
+	listenFDsStart = 3
+)
+
+// InheritedListener is a [net.Listener] which is passed to the current
+// process by its parent, e.g. systemd, using the socket activation protocol.
+type InheritedListener struct {
+	net.Listener
+	// Name of the listener as provided via the LISTEN_FDNAMES environment
+	// variable. It defaults to "unknown" when no name is provided.
+	Name string
+}
+
+var inherited struct {
+	once  sync.Once
+	list  []InheritedListener
+	err   error
+	ready *os.File
+
+	mut   sync.Mutex
+	taken map[net.Listener]struct{}
+}
+
+// InheritedListeners returns the [InheritedListener]s that are passed to the
+// current process using the LISTEN_FDS, LISTEN_PID and LISTEN_FDNAMES
+// environment variables, as described in sd_listen_fds(3), or by a parent
+// process which is upgraded using [Upgrader]. The environment variables are
+// unset after they are read, so they are not passed on to any child processes.
+// Subsequent calls return the same result.
+// An empty slice is returned when no listeners are passed to the process.
+func InheritedListeners() ([]InheritedListener, error) {
+	inherited.once.Do(func() {
+		inherited.list, inherited.err = inheritListeners(os.Getpid(), os.Getppid(), os.Getenv)
+		if inherited.err == nil && os.Getenv(envUpgradePPID) == strconv.Itoa(os.Getppid()) {
+			// the upgrading parent process passes the write end of a pipe
+			// directly after the listeners, see Upgrader.Ready
+			inherited.ready = os.NewFile(uintptr(listenFDsStart+len(inherited.list)), "ready")
+		}
+
+		_ = os.Unsetenv(envListenPID)
+		_ = os.Unsetenv(envListenFDs)
+		_ = os.Unsetenv(envListenFDNames)
+		_ = os.Unsetenv(envUpgradePPID)
+	})
+	return inherited.list, inherited.err
+}
+
+func inheritListeners(pid, ppid int, getenv func(string) string) ([]InheritedListener, error) {
+	if v := getenv(envListenPID); v != "" {
+		if p, err := strconv.Atoi(v); err != nil {
+			return nil, errors.Wrap(err, ErrInheritListeners)
+		} else if p != pid {
+			// listeners are meant for another process
+			return nil, nil
+		}
+	} else if v = getenv(envUpgradePPID); v != "" {
+		if p, err := strconv.Atoi(v); err != nil {
+			return nil, errors.Wrap(err, ErrInheritListeners)
+		} else if p != ppid {
+			// listeners are passed to another process
+			return nil, nil
+		}
+	} else {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(getenv(envListenFDs))
+	if err != nil {
+		return nil, errors.Wrap(err, ErrInheritListeners)
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	var names []string
+	if v := getenv(envListenFDNames); v != "" {
+		names = strings.Split(v, ":")
+	}
+
+	list := make([]InheritedListener, 0, n)
+	for i := 0; i < n; i++ {
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		f := os.NewFile(uintptr(listenFDsStart+i), name)
+		l, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			for _, il := range list {
+				_ = il.Close()
+			}
+			return nil, errors.Wrap(err, ErrInheritListeners)
+		}
+
+		list = append(list, InheritedListener{
+			Listener: l,
+			Name:     name,
+		})
+	}
+	return list, nil
+}
+
+// WithInheritedListener makes the [Server] accept connections on the
+// [InheritedListener]s with the provided name, instead of listening on
+// [Server.Addr] when started with [Server.Run]. All [InheritedListener]s are
+// used when name is empty. The [Server] listens on [Server.Addr] as usual when
+// no matching [InheritedListener]s are passed to the process.
+// TLS is used when [ShouldUseTLS] reports it is properly configured.
+// The [InheritedListener]s are closed once the [Server] has stopped serving,
+// after which a next run only listens on [Server.Addr] when it is not empty.
+func WithInheritedListener(name string) Option {
+	return optionFunc(func(srv *Server) error {
+		list, err := InheritedListeners()
+		if err != nil {
+			return err
+		}
+
+		inherited.mut.Lock()
+		defer inherited.mut.Unlock()
+
+		for _, il := range list {
+			if name != "" && il.Name != name {
+				continue
+			}
+			if _, taken := inherited.taken[il.Listener]; taken {
+				continue
+			}
+
+			srv.inherited = append(srv.inherited, il.Listener)
+			takeInherited(il.Listener)
+		}
+		return nil
+	})
+}
+
+// inheritedListenerFor returns an [InheritedListener] which listens on the
+// same TCP address or unix domain socket as addr, so the [Server] can continue
+// to accept connections on sockets passed by its parent process without
+// binding to addr again. It returns nil when there is no such listener, or
+// when it is already taken by another [Server].
+func inheritedListenerFor(addr Address) net.Listener {
+	list, _ := InheritedListeners()
+	if len(list) == 0 {
+		return nil
+	}
+
+	inherited.mut.Lock()
+	defer inherited.mut.Unlock()
+
+	for _, il := range list {
+		if _, taken := inherited.taken[il.Listener]; taken {
+			continue
+		}
+		if matchAddr(il.Addr(), addr) {
+			takeInherited(il.Listener)
+			return il.Listener
+		}
+	}
+	return nil
+}
+
+func takeInherited(l net.Listener) {
+	if inherited.taken == nil {
+		inherited.taken = make(map[net.Listener]struct{})
+	}
+	inherited.taken[l] = struct{}{}
+}
+
+func matchAddr(la net.Addr, addr Address) bool {
+	if addr.IsUnix() {
+		have, ok := la.(*net.UnixAddr)
+		return ok && have.Name == addr.Addr
+	}
+
+	have, ok := la.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	want, err := net.ResolveTCPAddr(addr.Network, addr.Addr)
+	if err != nil || want.Port == 0 || want.Port != have.Port {
+		return false
+	}
+	if want.IP == nil || want.IP.IsUnspecified() {
+		return have.IP.IsUnspecified()
+	}
+	return want.IP.Equal(have.IP)
+}
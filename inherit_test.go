@@ -0,0 +1,114 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInheritListeners(t *testing.T) {
+	env := func(m map[string]string) func(string) string {
+		return func(k string) string { return m[k] }
+	}
+
+	t.Run("none", func(t *testing.T) {
+		list, err := inheritListeners(1, 0, env(nil))
+		assert.NoError(t, err)
+		assert.Empty(t, list)
+	})
+	t.Run("other pid", func(t *testing.T) {
+		list, err := inheritListeners(1, 0, env(map[string]string{
+			envListenPID: "2",
+			envListenFDs: "1",
+		}))
+		assert.NoError(t, err)
+		assert.Empty(t, list)
+	})
+	t.Run("invalid pid", func(t *testing.T) {
+		_, err := inheritListeners(1, 0, env(map[string]string{
+			envListenPID: "foo",
+		}))
+		assert.ErrorIs(t, err, ErrInheritListeners)
+	})
+	t.Run("invalid fds", func(t *testing.T) {
+		_, err := inheritListeners(1, 0, env(map[string]string{
+			envListenPID: "1",
+			envListenFDs: "bar",
+		}))
+		assert.ErrorIs(t, err, ErrInheritListeners)
+	})
+}
+
+const envInheritHelper = "SERV_TEST_INHERIT_HELPER"
+
+func TestInheritedListeners(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("socket activation is not supported on windows")
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	f, err := l.(*net.TCPListener).File()
+	require.NoError(t, err)
+	defer f.Close()
+
+	// the shell sets LISTEN_PID to its own pid before replacing itself with
+	// the test binary, similar to how systemd passes sockets
+	cmd := exec.Command("/bin/sh", "-c", `LISTEN_PID=$$ exec "$0" "$@"`,
+		os.Args[0], "-test.run=^TestInheritedListenersHelper$",
+	)
+	cmd.Env = append(os.Environ(),
+		envInheritHelper+"=1",
+		envListenFDs+"=1",
+		envListenFDNames+"=web",
+	)
+	cmd.ExtraFiles = []*os.File{f}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	require.NoError(t, cmd.Start())
+
+	resp, err := http.Get("http://" + l.Addr().String())
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, "web", string(body))
+	assert.NoError(t, cmd.Wait())
+}
+
+func TestInheritedListenersHelper(t *testing.T) {
+	if os.Getenv(envInheritHelper) != "1" {
+		t.Skip("helper process")
+	}
+
+	list, err := InheritedListeners()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "web", list[0].Name)
+	assert.Empty(t, os.Getenv(envListenFDs))
+
+	var srv *Server
+	srv, err = New(
+		WithInheritedListener("web"),
+		WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(list[0].Name))
+			go func() { _ = srv.Shutdown(context.Background()) }()
+		})),
+	)
+	require.NoError(t, err)
+	assert.NoError(t, srv.Run())
+}
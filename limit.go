@@ -0,0 +1,393 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-pogo/errors"
+)
+
+const (
+	ErrMaxConns      errors.Msg = "maximum number of connections reached"
+	ErrMaxConnsPerIP errors.Msg = "maximum number of connections per ip reached"
+	ErrMaxAcceptRate errors.Msg = "maximum accept rate reached"
+	ErrInvalidPolicy errors.Msg = "invalid limit policy"
+)
+
+// LimitPolicy determines how connections over [Config.MaxConns] or
+// [Config.MaxAcceptRate] are handled.
+type LimitPolicy uint8
+
+const (
+	// LimitReject accepts and immediately closes connections over the limit.
+	LimitReject LimitPolicy = iota
+	// LimitQueue stops accepting connections until they are within the
+	// limit again. Pending connections are queued by the operating system,
+	// in the listener's backlog.
+	LimitQueue
+)
+
+var _ flag.Value = (*LimitPolicy)(nil)
+
+func (p LimitPolicy) String() string {
+	switch p {
+	case LimitReject:
+		return "reject"
+	case LimitQueue:
+		return "queue"
+	default:
+		panic(fmt.Sprintf("serv: %d is not a valid LimitPolicy", p))
+	}
+}
+
+// Set parses s as a [LimitPolicy], it implements [flag.Value].
+func (p *LimitPolicy) Set(s string) error {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "reject", "":
+		*p = LimitReject
+	case "queue":
+		*p = LimitQueue
+	default:
+		return errors.Newf("%w %q", ErrInvalidPolicy, s)
+	}
+	return nil
+}
+
+func (p LimitPolicy) MarshalText() ([]byte, error) { return []byte(p.String()), nil }
+
+func (p *LimitPolicy) UnmarshalText(text []byte) error { return p.Set(string(text)) }
+
+// LimitLogger is an optional interface a [Logger] can implement to log
+// connections that are rejected because they exceed one of the connection
+// limits of [Config]. Rejected connections are not logged individually, but
+// are counted per reason and logged at most once per second.
+type LimitLogger interface {
+	LogServerConnsRejected(name string, n uint64, reason error)
+}
+
+// rejectLogInterval is the interval in which rejected connections are counted
+// before they are logged, see [LimitLogger].
+const rejectLogInterval = time.Second
+
+type connLimits struct {
+	maxConns      int
+	maxConnsPerIP int
+	maxAcceptRate int
+	policy        LimitPolicy
+}
+
+// enabled indicates any of the limits is set.
+func (lim connLimits) enabled() bool {
+	return lim.maxConns > 0 || lim.maxConnsPerIP > 0 || lim.maxAcceptRate > 0
+}
+
+// connLimiter enforces the connection limits of a [Config] on all listeners
+// of a [Server]. Connections are counted when they are accepted and released
+// when their [http.ConnState] changes to closed. Hijacked connections are
+// released once the handler that hijacked them returns.
+type connLimiter struct {
+	limits   atomic.Pointer[connLimits]
+	rejected atomic.Uint64
+	// acquired contains the connections that are counted
+	acquired sync.Map
+
+	mut   sync.Mutex
+	conns int
+	perIP map[string]int
+	// tokens available for accepting connections within the maximum rate
+	tokens float64
+	last   time.Time
+	// wake is closed and renewed when a connection is released or the
+	// limits change
+	wake chan struct{}
+	// unlogged contains the number of rejected connections per reason,
+	// which are not yet logged
+	unlogged map[error]uint64
+}
+
+// set updates the limits to those of cfg.
+func (cl *connLimiter) set(cfg *Config) {
+	cl.limits.Store(&connLimits{
+		maxConns:      cfg.MaxConns,
+		maxConnsPerIP: cfg.MaxConnsPerIP,
+		maxAcceptRate: cfg.MaxAcceptRate,
+		policy:        cfg.LimitPolicy,
+	})
+
+	cl.mut.Lock()
+	cl.notify()
+	cl.mut.Unlock()
+}
+
+func (cl *connLimiter) load() connLimits {
+	if lim := cl.limits.Load(); lim != nil {
+		return *lim
+	}
+	return connLimits{}
+}
+
+// notify wakes any waiting listeners. The limiter's lock must be held when
+// calling notify.
+func (cl *connLimiter) notify() {
+	if cl.wake != nil {
+		close(cl.wake)
+		cl.wake = nil
+	}
+}
+
+// takeToken takes a token from the rate limiter's bucket, which is refilled
+// with rate tokens per second, up to a maximum of rate tokens. When no token
+// is available, takeToken returns the duration until it is. The limiter's
+// lock must be held when calling takeToken.
+func (cl *connLimiter) takeToken(rate int) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+
+	now := time.Now()
+	if cl.last.IsZero() {
+		cl.tokens = float64(rate)
+	} else {
+		cl.tokens += now.Sub(cl.last).Seconds() * float64(rate)
+		if cl.tokens > float64(rate) {
+			cl.tokens = float64(rate)
+		}
+	}
+	cl.last = now
+
+	if cl.tokens < 1 {
+		return time.Duration((1 - cl.tokens) / float64(rate) * float64(time.Second))
+	}
+	cl.tokens--
+	return 0
+}
+
+// wait blocks until a connection can be accepted within the limits of
+// [LimitQueue]. It returns [net.ErrClosed] when done is closed before that.
+// As connections are counted after they are accepted, the number of
+// connections may briefly exceed the maximum by the number of listeners that
+// were waiting at the same time.
+func (cl *connLimiter) wait(done <-chan struct{}) error {
+	for {
+		lim := cl.load()
+
+		cl.mut.Lock()
+		var delay time.Duration
+		if lim.maxConns > 0 && cl.conns >= lim.maxConns {
+			delay = -1
+		} else if delay = cl.takeToken(lim.maxAcceptRate); delay == 0 {
+			cl.mut.Unlock()
+			return nil
+		}
+
+		if cl.wake == nil {
+			cl.wake = make(chan struct{})
+		}
+		wake := cl.wake
+		cl.mut.Unlock()
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if delay > 0 {
+			timer = time.NewTimer(delay)
+			timeout = timer.C
+		}
+
+		var err error
+		select {
+		case <-wake:
+		case <-timeout:
+		case <-done:
+			err = net.ErrClosed
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// acquire counts conn when it is within the limits, or returns the reason
+// why it is not. When queued is true, the limits are already checked using
+// wait, and only the limit per IP is checked.
+func (cl *connLimiter) acquire(conn net.Conn, queued bool) error {
+	lim := cl.load()
+	ip := remoteIP(conn)
+
+	cl.mut.Lock()
+	defer cl.mut.Unlock()
+
+	var err error
+	switch {
+	case lim.maxConnsPerIP > 0 && ip != "" && cl.perIP[ip] >= lim.maxConnsPerIP:
+		err = ErrMaxConnsPerIP
+	case queued:
+	case lim.maxConns > 0 && cl.conns >= lim.maxConns:
+		err = ErrMaxConns
+	case cl.takeToken(lim.maxAcceptRate) != 0:
+		err = ErrMaxAcceptRate
+	}
+	if err != nil {
+		cl.rejected.Add(1)
+		return err
+	}
+
+	cl.conns++
+	if ip != "" {
+		if cl.perIP == nil {
+			cl.perIP = make(map[string]int)
+		}
+		cl.perIP[ip]++
+	}
+	cl.acquired.Store(conn, struct{}{})
+	return nil
+}
+
+// release releases the slot of a connection which is acquired earlier. It
+// does nothing when conn is not acquired, e.g. because it is accepted while
+// there were no limits.
+func (cl *connLimiter) release(conn net.Conn) {
+	conn = baseConn(conn)
+	if _, ok := cl.acquired.LoadAndDelete(conn); !ok {
+		return
+	}
+
+	ip := remoteIP(conn)
+	cl.mut.Lock()
+	defer cl.mut.Unlock()
+
+	cl.conns--
+	if n := cl.perIP[ip]; n > 1 {
+		cl.perIP[ip] = n - 1
+	} else {
+		delete(cl.perIP, ip)
+	}
+	cl.notify()
+}
+
+// remoteIP returns the IP address of the remote end of conn. For connections
+// from a trusted proxy, this is the address of the proxy and not the client's
+// address from its PROXY protocol header, which is not yet read when the
+// connection is accepted.
+func remoteIP(conn net.Conn) string {
+	if pc := findProxyConn(conn); pc != nil {
+		conn = pc.Conn
+	}
+	addr, ok := conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return ""
+	}
+	return addr.IP.String()
+}
+
+// baseConn returns the [net.Conn] that is accepted from the listener, which
+// conn wraps, e.g. when it is a [tls.Conn] or a connection from a trusted
+// proxy.
+func baseConn(conn net.Conn) net.Conn {
+	for {
+		c, ok := conn.(interface{ NetConn() net.Conn })
+		if !ok {
+			return conn
+		}
+		conn = c.NetConn()
+	}
+}
+
+// limitListener enforces the connection limits of its [Server] on the
+// connections accepted from its [net.Listener]. It is only used when any of
+// the limits is set, so connections do not need to be counted otherwise.
+type limitListener struct {
+	net.Listener
+	srv    *Server
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newLimitListener(l net.Listener, srv *Server) *limitListener {
+	return &limitListener{
+		Listener: l,
+		srv:      srv,
+		closed:   make(chan struct{}),
+	}
+}
+
+func (ll *limitListener) Accept() (net.Conn, error) {
+	lim := &ll.srv.limiter
+	for {
+		queued := lim.load().policy == LimitQueue
+		if queued {
+			if err := lim.wait(ll.closed); err != nil {
+				return nil, err
+			}
+		}
+
+		conn, err := ll.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		if err = lim.acquire(conn, queued); err != nil {
+			ll.reject(conn, err)
+			continue
+		}
+		return conn, nil
+	}
+}
+
+// reject closes conn and counts it as rejected for reason. The first
+// rejection within rejectLogInterval schedules logRejected, so a flood of
+// rejected connections does not slow down accepting with any logging.
+func (ll *limitListener) reject(conn net.Conn, reason error) {
+	_ = conn.Close()
+	if _, ok := ll.srv.logger().(LimitLogger); !ok {
+		return
+	}
+
+	cl := &ll.srv.limiter
+	cl.mut.Lock()
+	defer cl.mut.Unlock()
+
+	if cl.unlogged == nil {
+		cl.unlogged = make(map[error]uint64, 1)
+		time.AfterFunc(rejectLogInterval, ll.srv.logRejected)
+	}
+	cl.unlogged[reason]++
+}
+
+func (ll *limitListener) Close() error {
+	ll.once.Do(func() { close(ll.closed) })
+	return ll.Listener.Close()
+}
+
+// logRejected logs the number of connections that are rejected per reason
+// since the previous call to logRejected.
+func (srv *Server) logRejected() {
+	cl := &srv.limiter
+	cl.mut.Lock()
+	unlogged := cl.unlogged
+	cl.unlogged = nil
+	cl.mut.Unlock()
+
+	l, ok := srv.logger().(LimitLogger)
+	if !ok {
+		return
+	}
+
+	name := srv.Name()
+	for _, reason := range []error{ErrMaxConns, ErrMaxConnsPerIP, ErrMaxAcceptRate} {
+		if n := unlogged[reason]; n != 0 {
+			l.LogServerConnsRejected(name, n, reason)
+		}
+	}
+}
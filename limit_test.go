@@ -0,0 +1,225 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitPolicy(t *testing.T) {
+	for _, p := range []LimitPolicy{LimitReject, LimitQueue} {
+		t.Run(p.String(), func(t *testing.T) {
+			var have LimitPolicy
+			require.NoError(t, have.Set(p.String()))
+			assert.Equal(t, p, have)
+		})
+	}
+	t.Run("invalid", func(t *testing.T) {
+		var p LimitPolicy
+		assert.ErrorIs(t, p.Set("foo"), ErrInvalidPolicy)
+		assert.Panics(t, func() { _ = LimitPolicy(9).String() })
+	})
+}
+
+func TestConnLimiter_takeToken(t *testing.T) {
+	var cl connLimiter
+	assert.Zero(t, cl.takeToken(0))
+	assert.Zero(t, cl.takeToken(2))
+	assert.Zero(t, cl.takeToken(2))
+	assert.Positive(t, cl.takeToken(2))
+
+	cl.last = cl.last.Add(-time.Second)
+	assert.Zero(t, cl.takeToken(2))
+}
+
+// rejectLogger records the calls to LogServerConnsRejected.
+type rejectLogger struct {
+	Logger
+	mut   sync.Mutex
+	calls []string
+}
+
+func (l *rejectLogger) LogServerConnsRejected(name string, n uint64, reason error) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	l.calls = append(l.calls, fmt.Sprintf("%s %d %s", name, n, reason))
+}
+
+func (l *rejectLogger) get() []string {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return l.calls
+}
+
+func TestLimitListener_reject(t *testing.T) {
+	log := &rejectLogger{Logger: NopLogger()}
+	srv, err := New(WithName("foo"), WithLogger(log))
+	require.NoError(t, err)
+
+	ll := newLimitListener(nil, srv)
+	for i := 0; i < 3; i++ {
+		c1, c2 := net.Pipe()
+		ll.reject(c1, ErrMaxConns)
+		_ = c2.Close()
+	}
+	c1, c2 := net.Pipe()
+	ll.reject(c1, ErrMaxAcceptRate)
+	_ = c2.Close()
+
+	assert.Empty(t, log.get(), "rejections should not be logged immediately")
+	require.Eventually(t, func() bool {
+		return len(log.get()) == 2
+	}, 2*rejectLogInterval, 10*time.Millisecond)
+	assert.Equal(t, []string{
+		"foo 3 " + ErrMaxConns.Error(),
+		"foo 1 " + ErrMaxAcceptRate.Error(),
+	}, log.get())
+}
+
+func TestServer_limits(t *testing.T) {
+	run := func(t *testing.T, cfg Config) *Server {
+		srv, err := New(&cfg, WithHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+		require.NoError(t, err)
+		srv.Addr = "127.0.0.1:0"
+
+		done := make(chan error, 1)
+		go func() { done <- srv.Run() }()
+		require.NoError(t, srv.WaitReady(context.Background()))
+
+		t.Cleanup(func() {
+			assert.NoError(t, srv.Close())
+			assert.NoError(t, <-done)
+		})
+		return srv
+	}
+	dial := func(t *testing.T, srv *Server) net.Conn {
+		conn, err := net.Dial("tcp", srv.ListenAddr().String())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+		return conn
+	}
+	get := func(t *testing.T, conn net.Conn) (int, error) {
+		if _, err := io.WriteString(conn, "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n"); err != nil {
+			return 0, err
+		}
+
+		_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		if err != nil {
+			return 0, err
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	tests := map[string]Config{
+		"max conns":        {MaxConns: 1},
+		"max conns per ip": {MaxConnsPerIP: 1},
+		"max accept rate":  {MaxAcceptRate: 1},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := run(t, cfg)
+
+			status, err := get(t, dial(t, srv))
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, status)
+
+			// connection is closed, either with EOF or a reset
+			_, err = get(t, dial(t, srv))
+			var netErr net.Error
+			if assert.Error(t, err, "connection should be rejected") && errors.As(err, &netErr) {
+				assert.False(t, netErr.Timeout(), "connection should be rejected")
+			}
+			assert.Equal(t, uint64(1), srv.Stats().Rejected)
+		})
+	}
+
+	t.Run("release", func(t *testing.T) {
+		srv := run(t, Config{MaxConns: 1})
+		conn := dial(t, srv)
+		_, err := get(t, conn)
+		require.NoError(t, err)
+		_ = conn.Close()
+
+		assert.Eventually(t, func() bool {
+			_, err := get(t, dial(t, srv))
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+	})
+	t.Run("no limits", func(t *testing.T) {
+		srv := run(t, Config{})
+		_, err := get(t, dial(t, srv))
+		require.NoError(t, err)
+
+		srv.limiter.mut.Lock()
+		assert.Zero(t, srv.limiter.conns, "connections should not be counted")
+		srv.limiter.mut.Unlock()
+	})
+	t.Run("hijacked", func(t *testing.T) {
+		release := make(chan struct{})
+		srv, err := New(&Config{MaxConns: 1}, WithHandler(http.HandlerFunc(func(wri http.ResponseWriter, _ *http.Request) {
+			conn, _, err := http.NewResponseController(wri).Hijack()
+			if assert.NoError(t, err) {
+				_, _ = io.WriteString(conn, "HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n")
+				<-release
+				_ = conn.Close()
+			}
+		})))
+		require.NoError(t, err)
+		srv.Addr = "127.0.0.1:0"
+
+		done := make(chan error, 1)
+		go func() { done <- srv.Run() }()
+		require.NoError(t, srv.WaitReady(context.Background()))
+
+		_, err = get(t, dial(t, srv))
+		require.NoError(t, err)
+		_, err = get(t, dial(t, srv))
+		assert.Error(t, err, "hijacked connection should count towards the limit")
+
+		close(release)
+		assert.Eventually(t, func() bool {
+			return srv.Stats().HijackedActive == 0
+		}, time.Second, time.Millisecond)
+		_, err = get(t, dial(t, srv))
+		assert.NoError(t, err)
+
+		assert.NoError(t, srv.Close())
+		assert.NoError(t, <-done)
+	})
+	t.Run("queue", func(t *testing.T) {
+		srv := run(t, Config{MaxConns: 1, LimitPolicy: LimitQueue})
+		conn := dial(t, srv)
+		_, err := get(t, conn)
+		require.NoError(t, err)
+
+		queued := dial(t, srv)
+		_, err = get(t, queued)
+		var netErr net.Error
+		require.ErrorAs(t, err, &netErr)
+		assert.True(t, netErr.Timeout(), "connection should be queued")
+
+		_ = conn.Close()
+		_ = queued.SetReadDeadline(time.Now().Add(time.Second))
+		resp, err := http.ReadResponse(bufio.NewReader(queued), nil)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Zero(t, srv.Stats().Rejected)
+	})
+}
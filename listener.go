@@ -0,0 +1,380 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"io/fs"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-pogo/errors"
+)
+
+const (
+	ErrListen      errors.Msg = "unable to listen"
+	ErrNoEndpoints errors.Msg = "no endpoints to listen on"
+)
+
+var _ Option = (*Endpoint)(nil)
+
+// Endpoint describes an additional address or [net.Listener] on which a
+// [Server] accepts incoming connections when it is started with [Server.Run].
+// Endpoint implements [Option] and can be provided to [New] or [Server.With]
+// multiple times.
+type Endpoint struct {
+	// Addr optionally specifies the address to listen on, in the string form
+	// of an [Address]. It is ignored when Listener is set.
+	// See [net.Dial] for details of the address format.
+	Addr string
+	// Listener optionally specifies an already open [net.Listener] to accept
+	// connections on. The [Server] takes ownership of Listener and closes it
+	// when the [Server] is shut down or closed. A closed Listener cannot be
+	// reused, so the [Endpoint] is removed from the [Server] once it has
+	// stopped serving and is not used when the [Server] is run again.
+	Listener net.Listener
+	// TLS indicates connections should be served using TLS. The [Server]'s
+	// [http.Server.TLSConfig] is used to configure TLS.
+	TLS bool
+}
+
+func (ep Endpoint) apply(srv *Server) error {
+	srv.endpoints = append(srv.endpoints, ep)
+	return nil
+}
+
+// listen returns the [Endpoint]'s [net.Listener] or creates a new one which
+// listens on its Addr, similar to [http.Server.ListenAndServe]. An
+// [InheritedListener] which already listens on Addr is used instead of
+// creating a new [net.Listener]. Unix domain sockets are created using
+// socketMode, when it is not zero.
+func (ep Endpoint) listen(socketMode fs.FileMode) (net.Listener, error) {
+	if ep.Listener != nil {
+		return ep.Listener, nil
+	}
+
+	addr, err := ParseAddress(ep.Addr)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrListen)
+	}
+	if addr.Addr == "" {
+		if ep.TLS {
+			addr.Addr = ":https"
+		} else {
+			addr.Addr = ":http"
+		}
+	}
+
+	if l := inheritedListenerFor(addr); l != nil {
+		return l, nil
+	}
+
+	var l net.Listener
+	if addr.IsUnix() {
+		l, err = listenUnix(addr.Addr, socketMode)
+	} else {
+		l, err = net.Listen(addr.Network, addr.Addr)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, ErrListen)
+	}
+	return l, nil
+}
+
+// runEndpoints returns all [Endpoint]s the [Server] should listen on when
+// started with [Server.Run]. Its primary [Endpoint] is based on [Server.Addr]
+// and is omitted when Addr is empty and additional [Endpoint]s are provided.
+// Any inherited listeners replace the primary [Endpoint]. The primary
+// [Endpoint] is also omitted when Addr is empty and all owned listeners are
+// released, see releaseListeners.
+func (srv *Server) runEndpoints() []Endpoint {
+	useTLS := ShouldUseTLS(srv.TLSConfig)
+	eps := make([]Endpoint, 0, len(srv.endpoints)+len(srv.inherited)+1)
+	if len(srv.inherited) != 0 {
+		for _, l := range srv.inherited {
+			eps = append(eps, Endpoint{Listener: l, TLS: useTLS})
+		}
+	} else if srv.Addr != "" || (len(srv.endpoints) == 0 && !srv.released) {
+		eps = append(eps, Endpoint{Addr: srv.Addr, TLS: useTLS})
+	}
+	return append(eps, srv.endpoints...)
+}
+
+// releaseListeners removes the inherited listeners and the [Endpoint]s with a
+// Listener, which are closed after serving lns. These listeners are owned by
+// the [Server] and cannot be served again by a next run.
+func (srv *Server) releaseListeners(lns []boundListener) {
+	closed := make(map[net.Listener]struct{}, len(lns))
+	for _, bl := range lns {
+		closed[bl.Listener] = struct{}{}
+	}
+
+	srv.mut.Lock()
+	defer srv.mut.Unlock()
+
+	inherited := srv.inherited[:0]
+	for _, l := range srv.inherited {
+		if _, ok := closed[l]; ok {
+			srv.released = true
+			continue
+		}
+		inherited = append(inherited, l)
+	}
+	srv.inherited = inherited
+
+	eps := srv.endpoints[:0]
+	for _, ep := range srv.endpoints {
+		if _, ok := closed[ep.Listener]; ok && ep.Listener != nil {
+			srv.released = true
+			continue
+		}
+		eps = append(eps, ep)
+	}
+	srv.endpoints = eps
+}
+
+// readyListener calls ready once, when Accept is called for the first time.
+// [http.Server.Serve] registers a listener before it calls Accept, so from
+// then on shutting down the [http.Server] also closes the listener.
+type readyListener struct {
+	net.Listener
+	ready func()
+	once  sync.Once
+}
+
+func (rl *readyListener) Accept() (net.Conn, error) {
+	rl.once.Do(rl.ready)
+	return rl.Listener.Accept()
+}
+
+// deadlineListener is implemented by listeners like [net.TCPListener] and
+// [net.UnixListener], of which a blocked Accept can be interrupted without
+// closing the listener.
+type deadlineListener interface {
+	SetDeadline(t time.Time) error
+}
+
+// sharedListener hands over the connections of its underlying [net.Listener]
+// to the acceptors that are currently being served. This allows the
+// underlying [net.Listener] to remain open while the [Server] restarts, in
+// which case new connections are queued by the underlying [net.Listener]
+// until a new acceptor is served.
+//
+// Acceptors call Accept on the underlying [net.Listener] directly, so
+// connections are only accepted when an acceptor is waiting for one. When an
+// acceptor is closed, any blocked calls to Accept are interrupted by setting a
+// deadline on the underlying [net.Listener], after which the calls of the
+// acceptors that remain open are resumed. When the underlying [net.Listener]
+// does not support deadlines, a single goroutine calls Accept on behalf of
+// the waiting acceptors instead, see [sharedListener.acceptLoop].
+type sharedListener struct {
+	net.Listener
+	deadline deadlineListener
+	closed   chan struct{}
+	once     sync.Once
+
+	mut  sync.Mutex
+	cond *sync.Cond
+	// accepting is the number of calls to Accept on the underlying
+	// net.Listener which have not yet returned
+	accepting int
+	// interrupted indicates the blocked calls to Accept are interrupted by a
+	// deadline, which is reset once all of them have returned
+	interrupted bool
+
+	// demand and conns are used by acceptLoop, when the underlying
+	// net.Listener does not support deadlines
+	demand chan struct{}
+	conns  chan acceptResult
+	loop   sync.Once
+}
+
+type acceptResult struct {
+	conn net.Conn
+	err  error
+}
+
+func newSharedListener(l net.Listener) *sharedListener {
+	sl := &sharedListener{
+		Listener: l,
+		closed:   make(chan struct{}),
+	}
+	sl.cond = sync.NewCond(&sl.mut)
+	if dl, ok := l.(deadlineListener); ok {
+		sl.deadline = dl
+	} else {
+		sl.demand = make(chan struct{})
+		sl.conns = make(chan acceptResult)
+	}
+	return sl
+}
+
+// acceptor returns a new [net.Listener] which accepts connections from the
+// [sharedListener]. Closing it does not close the [sharedListener].
+func (sl *sharedListener) acceptor() net.Listener {
+	return &acceptor{
+		sharedListener: sl,
+		closed:         make(chan struct{}),
+	}
+}
+
+// accept calls Accept on the underlying [net.Listener] for acceptor a. It
+// waits until any interrupted calls have returned, so the deadline can be
+// reset first. Closing a afterwards interrupts the call.
+func (sl *sharedListener) accept(a *acceptor) (net.Conn, error) {
+	sl.mut.Lock()
+	for sl.interrupted {
+		if sl.accepting == 0 {
+			_ = sl.deadline.SetDeadline(time.Time{})
+			sl.interrupted = false
+			break
+		}
+		sl.cond.Wait()
+	}
+	if a.isClosed() {
+		sl.mut.Unlock()
+		return nil, net.ErrClosed
+	}
+	sl.accepting++
+	sl.mut.Unlock()
+
+	conn, err := sl.Listener.Accept()
+
+	sl.mut.Lock()
+	if sl.accepting--; sl.accepting == 0 {
+		sl.cond.Broadcast()
+	}
+	sl.mut.Unlock()
+	return conn, err
+}
+
+// interrupt interrupts any blocked calls to Accept on the underlying
+// [net.Listener], so the calls of closed acceptors can return.
+func (sl *sharedListener) interrupt() {
+	if sl.deadline == nil {
+		return
+	}
+
+	sl.mut.Lock()
+	if sl.accepting != 0 && !sl.interrupted {
+		sl.interrupted = true
+		_ = sl.deadline.SetDeadline(time.Now())
+	}
+	sl.mut.Unlock()
+}
+
+// acceptLoop calls Accept on the underlying [net.Listener] whenever an
+// acceptor is waiting for a connection. It is only used when the underlying
+// [net.Listener] does not support deadlines. A connection which is accepted
+// while its acceptor is closed in the meantime, is handed over to the next
+// waiting acceptor, or closed when the [sharedListener] is closed.
+func (sl *sharedListener) acceptLoop() {
+	for {
+		select {
+		case <-sl.demand:
+		case <-sl.closed:
+			return
+		}
+
+		conn, err := sl.Listener.Accept()
+		select {
+		case sl.conns <- acceptResult{conn, err}:
+		case <-sl.closed:
+			if conn != nil {
+				_ = conn.Close()
+			}
+			return
+		}
+		if err != nil && errors.Is(err, net.ErrClosed) {
+			return
+		}
+	}
+}
+
+// Close closes the underlying [net.Listener] and stops accepting connections.
+func (sl *sharedListener) Close() error {
+	var err error
+	sl.once.Do(func() {
+		close(sl.closed)
+		err = sl.Listener.Close()
+	})
+	return err
+}
+
+type acceptor struct {
+	*sharedListener
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (a *acceptor) Accept() (net.Conn, error) {
+	if a.sharedListener.deadline == nil {
+		return a.acceptAsync()
+	}
+
+	for {
+		conn, err := a.sharedListener.accept(a)
+		if err == nil {
+			// a connection that is accepted while the acceptor is closed
+			// is still returned, so it is served by the closing server
+			return conn, nil
+		}
+		if errors.Is(err, net.ErrClosed) || a.isClosed() {
+			return nil, net.ErrClosed
+		}
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			return nil, err
+		}
+		// interrupted because another acceptor is closed
+	}
+}
+
+// acceptAsync accepts a connection using [sharedListener.acceptLoop].
+func (a *acceptor) acceptAsync() (net.Conn, error) {
+	if a.isClosed() {
+		return nil, net.ErrClosed
+	}
+
+	a.loop.Do(func() { go a.acceptLoop() })
+	select {
+	case a.demand <- struct{}{}:
+	case res := <-a.conns:
+		return res.conn, res.err
+	case <-a.closed:
+		return nil, net.ErrClosed
+	case <-a.sharedListener.closed:
+		return nil, net.ErrClosed
+	}
+
+	select {
+	case res := <-a.conns:
+		return res.conn, res.err
+	case <-a.closed:
+		return nil, net.ErrClosed
+	case <-a.sharedListener.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// isClosed indicates the acceptor or its [sharedListener] is closed.
+func (a *acceptor) isClosed() bool {
+	select {
+	case <-a.closed:
+		return true
+	case <-a.sharedListener.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a *acceptor) Close() error {
+	a.once.Do(func() {
+		close(a.closed)
+		a.interrupt()
+	})
+	return nil
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpoint(t *testing.T) {
+	t.Run("apply", func(t *testing.T) {
+		var srv Server
+		require.NoError(t, srv.With(
+			Endpoint{Addr: ":8080"},
+			Endpoint{Addr: ":8443", TLS: true},
+		))
+		assert.Equal(t, []Endpoint{
+			{Addr: ":8080"},
+			{Addr: ":8443", TLS: true},
+		}, srv.endpoints)
+	})
+	t.Run("listen error", func(t *testing.T) {
+		_, err := Endpoint{Addr: "invalid:address:123"}.listen(0)
+		assert.ErrorIs(t, err, ErrListen)
+	})
+}
+
+func TestServer_runEndpoints(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		var srv Server
+		assert.Equal(t, []Endpoint{{}}, srv.runEndpoints())
+	})
+	t.Run("addr", func(t *testing.T) {
+		srv := Server{Addr: ":8080"}
+		srv.endpoints = []Endpoint{{Addr: ":9090"}}
+		assert.Equal(t, []Endpoint{{Addr: ":8080"}, {Addr: ":9090"}}, srv.runEndpoints())
+	})
+	t.Run("endpoints only", func(t *testing.T) {
+		var srv Server
+		srv.endpoints = []Endpoint{{Addr: ":9090"}}
+		assert.Equal(t, []Endpoint{{Addr: ":9090"}}, srv.runEndpoints())
+	})
+	t.Run("released", func(t *testing.T) {
+		srv := Server{released: true}
+		assert.Empty(t, srv.runEndpoints())
+	})
+}
+
+func TestServer_Run(t *testing.T) {
+	t.Run("multiple endpoints", func(t *testing.T) {
+		l1, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		l2, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		srv, err := New(
+			Endpoint{Listener: l1},
+			Endpoint{Listener: l2},
+			WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("ok"))
+			})),
+		)
+		require.NoError(t, err)
+
+		done := make(chan error, 1)
+		go func() { done <- srv.Run() }()
+
+		for _, l := range []net.Listener{l1, l2} {
+			resp, err := http.Get("http://" + l.Addr().String())
+			require.NoError(t, err)
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			assert.Equal(t, "ok", string(body))
+		}
+
+		assert.Equal(t, StateStarted, srv.State())
+		assert.NoError(t, srv.Shutdown(context.Background()))
+		select {
+		case err = <-done:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("Run did not return after Shutdown")
+		}
+		assert.Equal(t, StateClosed, srv.State())
+
+		assert.Empty(t, srv.endpoints, "closed listeners should be released")
+		assert.ErrorIs(t, srv.Run(), ErrNoEndpoints)
+	})
+	t.Run("release listener", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		srv, err := New(Endpoint{Addr: "127.0.0.1:0"}, Endpoint{Listener: l})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		for i := 0; i < 2; i++ {
+			done := make(chan error, 1)
+			go func() { done <- srv.Run() }()
+
+			require.NoError(t, srv.WaitReady(ctx))
+			srv.mut.RLock()
+			n := len(srv.listeners)
+			srv.mut.RUnlock()
+			assert.Equal(t, 2-i, n)
+
+			require.NoError(t, srv.Shutdown(ctx))
+			require.NoError(t, <-done)
+		}
+		assert.Equal(t, []Endpoint{{Addr: "127.0.0.1:0"}}, srv.endpoints)
+	})
+	t.Run("listen error", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer l.Close()
+
+		srv, err := New(Endpoint{Addr: l.Addr().String()})
+		require.NoError(t, err)
+		assert.ErrorIs(t, srv.Run(), ErrListen)
+		assert.Equal(t, StateErrored, srv.State())
+	})
+}
+
+// countingListener counts the calls to Accept of its embedded listener.
+type countingListener struct {
+	*net.TCPListener
+	accepts atomic.Int32
+}
+
+func (cl *countingListener) Accept() (net.Conn, error) {
+	cl.accepts.Add(1)
+	return cl.TCPListener.Accept()
+}
+
+// noDeadlineListener hides the SetDeadline method of its listener.
+type noDeadlineListener struct{ net.Listener }
+
+func TestSharedListener(t *testing.T) {
+	listen := func(t *testing.T) *net.TCPListener {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		return l.(*net.TCPListener)
+	}
+	dial := func(t *testing.T, l net.Listener) net.Conn {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+		return conn
+	}
+	accept := func(a net.Listener) <-chan acceptResult {
+		res := make(chan acceptResult, 1)
+		go func() {
+			conn, err := a.Accept()
+			res <- acceptResult{conn, err}
+		}()
+		return res
+	}
+
+	t.Run("accept on demand", func(t *testing.T) {
+		cl := &countingListener{TCPListener: listen(t)}
+		sl := newSharedListener(cl)
+		defer sl.Close()
+
+		a := sl.acceptor()
+		dial(t, sl)
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, int32(0), cl.accepts.Load(), "should not accept without a waiting acceptor")
+
+		res := <-accept(a)
+		require.NoError(t, res.err)
+		_ = res.conn.Close()
+		assert.Equal(t, int32(1), cl.accepts.Load())
+	})
+	t.Run("close acceptor", func(t *testing.T) {
+		cl := &countingListener{TCPListener: listen(t)}
+		sl := newSharedListener(cl)
+		defer sl.Close()
+
+		a1, a2 := sl.acceptor(), sl.acceptor()
+		res1, res2 := accept(a1), accept(a2)
+		require.Eventually(t, func() bool {
+			return cl.accepts.Load() == 2
+		}, time.Second, time.Millisecond)
+
+		require.NoError(t, a1.Close())
+		assert.ErrorIs(t, (<-res1).err, net.ErrClosed)
+
+		dial(t, sl)
+		res := <-res2
+		require.NoError(t, res.err, "other acceptor should keep accepting")
+		_ = res.conn.Close()
+
+		require.NoError(t, a2.Close())
+		accepts := cl.accepts.Load()
+		dial(t, sl)
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, accepts, cl.accepts.Load(), "should not accept after last acceptor is closed")
+
+		res = <-accept(sl.acceptor())
+		require.NoError(t, res.err, "connection should be queued for the next acceptor")
+		_ = res.conn.Close()
+	})
+	t.Run("without deadline", func(t *testing.T) {
+		sl := newSharedListener(noDeadlineListener{listen(t)})
+		defer sl.Close()
+
+		a := sl.acceptor()
+		dial(t, sl)
+		res := <-accept(a)
+		require.NoError(t, res.err)
+		_ = res.conn.Close()
+
+		res1 := accept(a)
+		require.NoError(t, a.Close())
+		assert.ErrorIs(t, (<-res1).err, net.ErrClosed)
+	})
+	t.Run("close", func(t *testing.T) {
+		sl := newSharedListener(listen(t))
+		res := accept(sl.acceptor())
+		require.NoError(t, sl.Close())
+		assert.ErrorIs(t, (<-res).err, net.ErrClosed)
+		assert.ErrorIs(t, (<-accept(sl.acceptor())).err, net.ErrClosed)
+	})
+}
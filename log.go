@@ -6,6 +6,10 @@ package serv
 
 import (
 	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Logger logs a [Server]'s lifecycle events.
@@ -16,6 +20,42 @@ type Logger interface {
 	LogServerClose(name string)
 }
 
+// LifecycleLogger is an optional interface a [Logger] can implement to log
+// additional lifecycle events of a [Server], such as the addresses its
+// listeners are bound to, errors that stop it from serving and the outcome
+// of a graceful shutdown.
+type LifecycleLogger interface {
+	// LogServerListen is called for each listener the [Server] serves on,
+	// with the actually bound address of the listener.
+	LogServerListen(name string, addr net.Addr)
+	// LogServerError is called when the [Server] has stopped serving
+	// because of err, and its [State] is set to [StateErrored].
+	LogServerError(name string, err error)
+	// LogServerShutdownComplete is called when a graceful shutdown has
+	// completed or timed out, with its duration and the number of
+	// connections that remained open.
+	LogServerShutdownComplete(name string, dur time.Duration, conns int64)
+	// LogServerForcedClose is called when conns connections are forcefully
+	// closed because the [Server] did not shut down gracefully in time.
+	LogServerForcedClose(name string, conns int64)
+}
+
+// UpgradeLogger is an optional interface a [Logger] can implement to log
+// upgrades performed by an [Upgrader].
+type UpgradeLogger interface {
+	LogServerUpgrade(name string, pid int)
+	LogServerUpgradeError(name string, err error)
+}
+
+// ConfigLogger is an optional interface a [Logger] can implement to log the
+// effective [Config] of a [Server] when it starts, and any updates to it
+// using [Server.UpdateConfig].
+type ConfigLogger interface {
+	LogServerConfig(name string, cfg Config)
+	LogServerConfigUpdate(name string, old, cfg Config)
+	LogServerConfigUpdateError(name string, err error)
+}
+
 type ErrorLoggerProvider interface {
 	ErrorLogger() *log.Logger
 }
@@ -77,12 +117,98 @@ func (l *logger) LogServerClose(name string) {
 	l.Println(l.name(name) + " closing")
 }
 
+func (l *logger) LogServerListen(name string, addr net.Addr) {
+	l.Println(l.name(name) + " listening on " + addr.String())
+}
+
+func (l *logger) LogServerError(name string, err error) {
+	l.Println(l.name(name) + " errored: " + err.Error())
+}
+
+func (l *logger) LogServerShutdownComplete(name string, dur time.Duration, conns int64) {
+	l.Printf("%s shut down in %s with %d remaining connection(s)\n", l.name(name), dur, conns)
+}
+
+func (l *logger) LogServerForcedClose(name string, conns int64) {
+	l.Printf("%s forcefully closed %d connection(s)\n", l.name(name), conns)
+}
+
+func (l *logger) LogServerUpgrade(name string, pid int) {
+	l.Println(l.name(name) + " upgraded to process " + strconv.Itoa(pid))
+}
+
+func (l *logger) LogServerUpgradeError(name string, err error) {
+	l.Println(l.name(name) + " failed to upgrade: " + err.Error())
+}
+
+func (l *logger) LogServerConfig(name string, cfg Config) {
+	var sb strings.Builder
+	sb.WriteString(l.name(name))
+	sb.WriteString(" config:")
+	for _, f := range cfg.fields() {
+		sb.WriteByte(' ')
+		sb.WriteString(f.key())
+		sb.WriteByte('=')
+		sb.WriteString(f.value.String())
+	}
+	l.Println(sb.String())
+}
+
+func (l *logger) LogServerConfigUpdate(name string, old, cfg Config) {
+	var sb strings.Builder
+	sb.WriteString(l.name(name))
+	sb.WriteString(" config updated:")
+
+	oldFields := old.fields()
+	for i, f := range cfg.fields() {
+		from, to := oldFields[i].value.String(), f.value.String()
+		if from == to {
+			continue
+		}
+		sb.WriteByte(' ')
+		sb.WriteString(f.key())
+		sb.WriteByte('=')
+		sb.WriteString(from)
+		sb.WriteString("->")
+		sb.WriteString(to)
+	}
+	l.Println(sb.String())
+}
+
+func (l *logger) LogServerConfigUpdateError(name string, err error) {
+	l.Println(l.name(name) + " failed to update config: " + err.Error())
+}
+
+func (l *logger) LogServerConnsRejected(name string, n uint64, reason error) {
+	l.Println(l.name(name) + " rejected " + strconv.FormatUint(n, 10) + " connection(s): " + reason.Error())
+}
+
+func (l *logger) LogServerCertReload(name, certFile string) {
+	l.Println(l.name(name) + " reloaded certificate " + certFile)
+}
+
+func (l *logger) LogServerCertReloadError(name, certFile string, err error) {
+	l.Println(l.name(name) + " failed to reload certificate " + certFile + ": " + err.Error())
+}
+
 // NopLogger returns a [Logger] that does nothing.
 func NopLogger() Logger { return new(nopLogger) }
 
 type nopLogger struct{}
 
-func (*nopLogger) LogServerStart(_, _ string)          {}
-func (*nopLogger) LogServerStartTLS(_, _, _, _ string) {}
-func (*nopLogger) LogServerShutdown(string)            {}
-func (*nopLogger) LogServerClose(string)               {}
+func (*nopLogger) LogServerStart(_, _ string)                             {}
+func (*nopLogger) LogServerStartTLS(_, _, _, _ string)                    {}
+func (*nopLogger) LogServerShutdown(string)                               {}
+func (*nopLogger) LogServerClose(string)                                  {}
+func (*nopLogger) LogServerListen(string, net.Addr)                       {}
+func (*nopLogger) LogServerError(string, error)                           {}
+func (*nopLogger) LogServerShutdownComplete(string, time.Duration, int64) {}
+func (*nopLogger) LogServerForcedClose(string, int64)                     {}
+func (*nopLogger) LogServerUpgrade(string, int)                           {}
+func (*nopLogger) LogServerUpgradeError(string, error)                    {}
+func (*nopLogger) LogServerConfig(string, Config)                         {}
+func (*nopLogger) LogServerConfigUpdate(_ string, _, _ Config)            {}
+func (*nopLogger) LogServerConfigUpdateError(string, error)               {}
+func (*nopLogger) LogServerConnsRejected(string, uint64, error)           {}
+func (*nopLogger) LogServerCertReload(_, _ string)                        {}
+func (*nopLogger) LogServerCertReloadError(_, _ string, _ error)          {}
@@ -5,9 +5,11 @@
 package serv
 
 import (
+	"bytes"
 	"io"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -33,3 +35,32 @@ func TestDefaultLogger(t *testing.T) {
 	want := log.Default()
 	assert.Same(t, want, DefaultLogger().(*logger).Logger)
 }
+
+func TestLogger_LogServerConfig(t *testing.T) {
+	var buf bytes.Buffer
+	NewLogger(log.New(&buf, "", 0)).(ConfigLogger).LogServerConfig("foo", Config{
+		ReadTimeout:    time.Second,
+		MaxHeaderBytes: 4096,
+	})
+	assert.Equal(t, "server foo config: read_timeout=1s read_header_timeout=0s "+
+		"write_timeout=0s idle_timeout=0s shutdown_timeout=0s drain_delay=0s max_header_bytes=4KiB "+
+		"max_conns=0 max_conns_per_ip=0 max_accept_rate=0 limit_policy=reject "+
+		"protocols=default http2_max_concurrent_streams=0 http2_max_read_frame_size=0 "+
+		"http2_send_ping_timeout=0s http2_ping_timeout=0s\n", buf.String())
+}
+
+func TestLogger_LogServerConfigUpdate(t *testing.T) {
+	var buf bytes.Buffer
+	old := defaultConfig
+	cfg := defaultConfig
+	cfg.IdleTimeout = time.Minute
+
+	NewLogger(log.New(&buf, "", 0)).(ConfigLogger).LogServerConfigUpdate("", old, cfg)
+	assert.Equal(t, "server config updated: idle_timeout=2m0s->1m0s\n", buf.String())
+}
+
+func TestLogger_LogServerConnsRejected(t *testing.T) {
+	var buf bytes.Buffer
+	NewLogger(log.New(&buf, "", 0)).(LimitLogger).LogServerConnsRejected("foo", 3, ErrMaxConns)
+	assert.Equal(t, "server foo rejected 3 connection(s): "+ErrMaxConns.Error()+"\n", buf.String())
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/go-pogo/serv"
+)
+
+const panicInvalidIdentityPattern = "middleware.RequireClientIdentity: invalid pattern "
+
+// RequireClientIdentity returns a [Wrapper] which only allows requests from
+// clients with a verified [serv.Identity] of which one of its names matches
+// one of the patterns. Patterns are matched using [path.Match], so a "*"
+// does not match a "/". This allows patterns like
+// "spiffe://example.org/ns/*/sa/billing" or "*.internal.example.org".
+// Other requests are replied to with an HTTP 403 "forbidden" status code.
+// Use [serv.WithClientAuth] to authenticate clients.
+func RequireClientIdentity(patterns ...string) Wrapper {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			panic(panicInvalidIdentityPattern + p)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+			id := serv.ClientIdentity(req.Context())
+			if id == nil || !matchIdentity(*id, patterns) {
+				http.Error(wri, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(wri, req)
+		})
+	}
+}
+
+func matchIdentity(id serv.Identity, patterns []string) bool {
+	for _, name := range id.Names() {
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-pogo/serv"
+	"github.com/go-pogo/serv/response"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireClientIdentity(t *testing.T) {
+	t.Run("invalid pattern", func(t *testing.T) {
+		assert.PanicsWithValue(t, panicInvalidIdentityPattern+"[", func() {
+			_ = RequireClientIdentity("[")
+		})
+	})
+
+	handler := RequireClientIdentity(
+		"spiffe://example.org/ns/*/sa/billing",
+		"*.internal",
+	)(response.NoContentHandler())
+
+	tests := map[string]struct {
+		id   *serv.Identity
+		want int
+	}{
+		"unauthenticated": {
+			want: http.StatusForbidden,
+		},
+		"spiffe id": {
+			id:   &serv.Identity{URIs: []string{"spiffe://example.org/ns/prod/sa/billing"}},
+			want: http.StatusNoContent,
+		},
+		"spiffe id mismatch": {
+			id:   &serv.Identity{URIs: []string{"spiffe://example.org/ns/prod/sa/other"}},
+			want: http.StatusForbidden,
+		},
+		"dns name": {
+			id:   &serv.Identity{DNSNames: []string{"billing.internal"}},
+			want: http.StatusNoContent,
+		},
+		"common name": {
+			id:   &serv.Identity{CommonName: "reports.internal"},
+			want: http.StatusNoContent,
+		},
+		"wildcard does not match slash": {
+			id:   &serv.Identity{URIs: []string{"spiffe://example.org/ns/a/b/sa/billing"}},
+			want: http.StatusForbidden,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := serv.ContextWithInfo(context.Background(), serv.Info{ClientIdentity: tc.id})
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+			assert.Equal(t, tc.want, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"net/http"
+
+	"github.com/go-pogo/errors"
+)
+
+const (
+	ErrUnableToPause  errors.Msg = "unable to pause server"
+	ErrUnableToResume errors.Msg = "unable to resume server"
+)
+
+// pauseRetryAfter is the value of the Retry-After header, in seconds, of
+// responses to requests that are received while the [Server] is paused.
+const pauseRetryAfter = "30"
+
+// Pause temporarily stops the [Server] from handling new requests, without
+// closing its listeners or connections, and changes its [State] to
+// [StatePaused]. Requests that are already being handled are not affected
+// and finish as usual. New requests are replied to with an HTTP 503
+// "service unavailable" status code and a Retry-After header, until the
+// [Server] is resumed using [Server.Resume], except for those handled by
+// [Server.HealthHandler], which reports the [Server] is paused.
+// A paused [Server] can still be shut down or closed. [Server.Restart]
+// resumes the [Server] once it is started again.
+// An [InvalidStateError] containing a [ErrUnableToPause] error is returned
+// when the [Server] is not started.
+func (srv *Server) Pause() error {
+	return srv.setPaused(true, StateStarted, ErrUnableToPause)
+}
+
+// Resume continues handling new requests after the [Server] is paused using
+// [Server.Pause], and changes its [State] back to [StateStarted].
+// An [InvalidStateError] containing a [ErrUnableToResume] error is returned
+// when the [Server] is not paused.
+func (srv *Server) Resume() error {
+	return srv.setPaused(false, StatePaused, ErrUnableToResume)
+}
+
+// setPaused changes the [State] of the [Server] to [StatePaused] when paused
+// is true, or to [StateStarted] otherwise. The current [State] must equal
+// from, otherwise an [InvalidStateError] containing err is returned.
+func (srv *Server) setPaused(paused bool, from State, err errors.Msg) error {
+	to := StateStarted
+	if paused {
+		to = StatePaused
+	}
+
+	srv.mut.Lock()
+	if state := srv.state; state != from {
+		srv.mut.Unlock()
+		return errors.WithStack(&InvalidStateError{
+			Err:   err,
+			State: state,
+		})
+	}
+
+	srv.paused.Store(paused)
+	event, ok := srv.setState(to, nil)
+	srv.mut.Unlock()
+	srv.emit(event, ok)
+	return nil
+}
+
+// servePaused replies to a request that is received while the [Server] is
+// paused.
+func servePaused(wri http.ResponseWriter) {
+	wri.Header().Set("Retry-After", pauseRetryAfter)
+	http.Error(wri, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Pause(t *testing.T) {
+	t.Run("not started", func(t *testing.T) {
+		var srv Server
+		assert.ErrorIs(t, srv.Pause(), ErrUnableToPause)
+		assert.ErrorIs(t, srv.Resume(), ErrUnableToResume)
+	})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv, err := New(WithHandler(http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+		_, _ = io.WriteString(wri, "ok")
+	})))
+	require.NoError(t, err)
+	srv.Addr = "127.0.0.1:0"
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Run() }()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	get := func(path string) *http.Response {
+		resp, err := http.Get("http://" + srv.ListenAddr().String() + path)
+		require.NoError(t, err)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	inflight := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get("http://" + srv.ListenAddr().String() + "/slow")
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		inflight <- resp
+	}()
+	<-started
+
+	require.NoError(t, srv.Pause())
+	assert.Equal(t, StatePaused, srv.State())
+	assert.Equal(t, StatePaused, srv.Stats().State)
+	assert.ErrorIs(t, srv.Pause(), ErrUnableToPause)
+
+	resp := get("/")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, pauseRetryAfter, resp.Header.Get("Retry-After"))
+
+	rec := httptest.NewRecorder()
+	srv.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "paused", rec.Body.String())
+
+	close(release)
+	if resp := <-inflight; assert.NotNil(t, resp) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "in-flight request should finish")
+	}
+
+	require.NoError(t, srv.Resume())
+	assert.Equal(t, StateStarted, srv.State())
+	assert.Equal(t, http.StatusOK, get("/").StatusCode)
+
+	require.NoError(t, srv.Pause())
+	require.NoError(t, srv.Shutdown(context.Background()))
+	assert.NoError(t, <-done)
+	assert.Equal(t, StateClosed, srv.State())
+}
+
+func TestServer_Pause_healthHandler(t *testing.T) {
+	mux := NewServeMux()
+	srv, err := New(mux)
+	require.NoError(t, err)
+	srv.Addr = "127.0.0.1:0"
+
+	mux.Handle("/healthz", srv.HealthHandler())
+	mux.HandleFunc("/", func(wri http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(wri, "ok")
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Run() }()
+	require.NoError(t, srv.WaitReady(context.Background()))
+	require.NoError(t, srv.Pause())
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get("http://" + srv.ListenAddr().String() + path)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return resp, string(body)
+	}
+
+	resp, body := get("/healthz")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "paused", body)
+	assert.Empty(t, resp.Header.Get("Retry-After"))
+
+	resp, _ = get("/")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, pauseRetryAfter, resp.Header.Get("Retry-After"))
+
+	require.NoError(t, srv.Shutdown(context.Background()))
+	assert.NoError(t, <-done)
+}
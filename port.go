@@ -150,21 +150,30 @@ func (p Port) Addr() string {
 }
 
 func (p Port) apply(srv *Server) error {
-	if srv.Addr == "" {
-		srv.Addr = p.Addr()
-		return nil
+	addr, err := ParseAddress(srv.Addr)
+	if err != nil {
+		return err
 	}
-	if !strings.ContainsRune(srv.Addr, ':') {
-		srv.Addr += p.Addr()
-		return nil
+	if addr.IsUnix() {
+		return errors.Newf("%w %q: unix domain socket has no port", ErrInvalidAddress, srv.Addr)
 	}
 
-	host, _, err := net.SplitHostPort(srv.Addr)
-	if err != nil && isMissingPort(err) {
-		host = srv.Addr
+	if addr.Addr == "" {
+		addr.Addr = p.Addr()
+	} else if !strings.ContainsRune(addr.Addr, ':') {
+		addr.Addr += p.Addr()
+	} else {
+		host, _, err := net.SplitHostPort(addr.Addr)
+		if err == nil {
+			addr.Addr = JoinHostPort(host, p)
+		} else if isMissingPort(err) {
+			addr.Addr += p.Addr()
+		} else {
+			addr.Addr = p.Addr()
+		}
 	}
 
-	srv.Addr = host + p.Addr()
+	srv.Addr = addr.String()
 	return nil
 }
 
@@ -0,0 +1,108 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"encoding"
+	"flag"
+	"strings"
+
+	"github.com/go-pogo/errors"
+)
+
+const ErrInvalidProtocol errors.Msg = "invalid protocol"
+
+// Protocols is a set of protocols a [Server] accepts connections for. The
+// zero value uses the default protocols of [http.Server], which are HTTP/1
+// and HTTP/2 over TLS.
+type Protocols uint8
+
+const (
+	// ProtocolHTTP1 is HTTP/1.0 and HTTP/1.1, over TLS or unencrypted.
+	ProtocolHTTP1 Protocols = 1 << iota
+	// ProtocolHTTP2 is HTTP/2 over TLS.
+	ProtocolHTTP2
+	// ProtocolUnencryptedHTTP2 is HTTP/2 over unencrypted connections, also
+	// known as h2c, using "prior knowledge". It requires Go 1.24 or later.
+	ProtocolUnencryptedHTTP2
+)
+
+const protocolsDefault = "default"
+
+// limits of the maximum HTTP/2 frame size, see RFC 9113 section 4.2
+const (
+	minHTTP2FrameSize = 16 << 10
+	maxHTTP2FrameSize = 1<<24 - 1
+)
+
+var protocolNames = [...]struct {
+	p    Protocols
+	name string
+}{
+	{ProtocolHTTP1, "http1"},
+	{ProtocolHTTP2, "http2"},
+	{ProtocolUnencryptedHTTP2, "h2c"},
+}
+
+var (
+	_ encoding.TextMarshaler   = (*Protocols)(nil)
+	_ encoding.TextUnmarshaler = (*Protocols)(nil)
+	_ flag.Value               = (*Protocols)(nil)
+)
+
+// Has indicates all protocols of q are in [Protocols] p.
+func (p Protocols) Has(q Protocols) bool { return p&q == q }
+
+// String returns the comma separated names of the protocols, e.g.
+// "http1,http2,h2c", or "default" when [Protocols] is zero.
+func (p Protocols) String() string {
+	if p == 0 {
+		return protocolsDefault
+	}
+
+	var sb strings.Builder
+	for _, pn := range protocolNames {
+		if !p.Has(pn.p) {
+			continue
+		}
+		if sb.Len() != 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(pn.name)
+	}
+	return sb.String()
+}
+
+// Set parses s as a comma separated list of the protocol names "http1",
+// "http2" and "h2c". An empty string or "default" results in a zero
+// [Protocols]. This method implements the [flag.Value] interface.
+func (p *Protocols) Set(s string) error {
+	var res Protocols
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || name == protocolsDefault {
+			continue
+		}
+
+		var found bool
+		for _, pn := range protocolNames {
+			if pn.name == name {
+				res |= pn.p
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.Newf("%w %q", ErrInvalidProtocol, name)
+		}
+	}
+
+	*p = res
+	return nil
+}
+
+func (p Protocols) MarshalText() ([]byte, error) { return []byte(p.String()), nil }
+
+func (p *Protocols) UnmarshalText(text []byte) error { return p.Set(string(text)) }
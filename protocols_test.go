@@ -0,0 +1,55 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtocols_String(t *testing.T) {
+	tests := map[Protocols]string{
+		0:                                        "default",
+		ProtocolHTTP1:                            "http1",
+		ProtocolHTTP1 | ProtocolHTTP2:            "http1,http2",
+		ProtocolHTTP2 | ProtocolUnencryptedHTTP2: "http2,h2c",
+		ProtocolHTTP1 | ProtocolUnencryptedHTTP2: "http1,h2c",
+		ProtocolHTTP1 | ProtocolHTTP2 | ProtocolUnencryptedHTTP2: "http1,http2,h2c",
+	}
+	for p, want := range tests {
+		t.Run(want, func(t *testing.T) {
+			assert.Equal(t, want, p.String())
+		})
+	}
+}
+
+func TestProtocols_Set(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    Protocols
+		wantErr bool
+	}{
+		"empty":    {input: ""},
+		"default":  {input: "default"},
+		"single":   {input: "http1", want: ProtocolHTTP1},
+		"multiple": {input: " HTTP1, h2c ", want: ProtocolHTTP1 | ProtocolUnencryptedHTTP2},
+		"all":      {input: "h2c,http2,http1", want: ProtocolHTTP1 | ProtocolHTTP2 | ProtocolUnencryptedHTTP2},
+		"invalid":  {input: "http1,http3", wantErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := ProtocolHTTP2
+			err := p.Set(tc.input)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidProtocol)
+				assert.Equal(t, ProtocolHTTP2, p, "should not be changed")
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, p)
+			}
+		})
+	}
+}
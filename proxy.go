@@ -0,0 +1,371 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-pogo/errors"
+)
+
+const (
+	ErrInvalidTrustedProxy errors.Msg = "invalid trusted proxy"
+	ErrInvalidProxyHeader  errors.Msg = "invalid proxy protocol header"
+)
+
+// Types of the TLVs of a PROXY protocol v2 header, as defined in section 2.2
+// of the PROXY protocol specification.
+const (
+	ProxyTLVALPN      byte = 0x01
+	ProxyTLVAuthority byte = 0x02
+	ProxyTLVCRC32C    byte = 0x03
+	ProxyTLVNoop      byte = 0x04
+	ProxyTLVUniqueID  byte = 0x05
+	ProxyTLVSSL       byte = 0x20
+	ProxyTLVNetNS     byte = 0x30
+)
+
+const (
+	// proxyV1MaxLen is the maximum length of a v1 header, including CRLF
+	proxyV1MaxLen = 107
+	proxyV2HdrLen = 16
+)
+
+var proxyV2Sig = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// ProxyHeader contains the information of a PROXY protocol header which is
+// received from a trusted proxy, see [WithProxyProtocol].
+type ProxyHeader struct {
+	// Version of the PROXY protocol, either 1 or 2.
+	Version int
+	// Source is the address of the client which is connected to the proxy.
+	// It is nil when the proxy does not relay a connection, e.g. when it
+	// performs a health check.
+	Source net.Addr
+	// Destination is the address of the proxy the client is connected to.
+	// It is nil when Source is nil.
+	Destination net.Addr
+	// TLVs contains the additional type-length-value fields of a v2 header.
+	TLVs []ProxyTLV
+}
+
+// ProxyTLV is a type-length-value field of a PROXY protocol v2 header.
+type ProxyTLV struct {
+	Type  byte
+	Value []byte
+}
+
+// TLV returns the value of the first TLV of type typ.
+func (h *ProxyHeader) TLV(typ byte) ([]byte, bool) {
+	for _, tlv := range h.TLVs {
+		if tlv.Type == typ {
+			return tlv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// SNI returns the server name the client has sent to the proxy, as
+// contained in the [ProxyTLVAuthority] TLV. Its returned value may be an
+// empty string.
+func (h *ProxyHeader) SNI() string {
+	v, _ := h.TLV(ProxyTLVAuthority)
+	return string(v)
+}
+
+type ctxProxyConnKey struct{}
+
+// ProxyHeaderFromContext returns the [ProxyHeader] of the connection of a
+// request, or nil when the connection is not from a trusted proxy.
+func ProxyHeaderFromContext(ctx context.Context) *ProxyHeader {
+	pc, _ := ctx.Value(ctxProxyConnKey{}).(*proxyConn)
+	if pc == nil {
+		return nil
+	}
+	hdr, _ := pc.header()
+	return hdr
+}
+
+// WithProxyProtocol makes the [Server] accept PROXY protocol v1 and v2
+// headers on connections from the trusted proxies, which are IP addresses or
+// CIDR ranges. The client address in the header replaces the remote address
+// of the connection, so it is available as [http.Request.RemoteAddr].
+// Connections from trusted proxies must start with a valid header; other
+// connections are served as usual. Use [ProxyHeaderFromContext] to access the
+// header, and its TLVs, from a request's context.
+//
+// The header is read from the connection once it is first used, limited by
+// [Config.ReadHeaderTimeout]. Connection limits per IP apply to the address
+// of the proxy.
+func WithProxyProtocol(trusted ...string) Option {
+	return optionFunc(func(srv *Server) error {
+		if len(trusted) == 0 {
+			return errors.Newf("%w: no trusted proxies provided", ErrInvalidTrustedProxy)
+		}
+
+		prefixes := make([]netip.Prefix, 0, len(trusted))
+		for _, s := range trusted {
+			p, err := parsePrefix(s)
+			if err != nil {
+				return errors.Newf("%w %q: %w", ErrInvalidTrustedProxy, s, err)
+			}
+			prefixes = append(prefixes, p)
+		}
+
+		srv.proxyTrusted = append(srv.proxyTrusted, prefixes...)
+		return nil
+	})
+}
+
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.ContainsRune(s, '/') {
+		p, err := netip.ParsePrefix(s)
+		return p.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// proxyListener wraps the connections of trusted proxies in a [proxyConn].
+type proxyListener struct {
+	net.Listener
+	trusted []netip.Prefix
+	timeout time.Duration
+}
+
+func newProxyListener(l net.Listener, trusted []netip.Prefix, timeout time.Duration) *proxyListener {
+	return &proxyListener{
+		Listener: l,
+		trusted:  trusted,
+		timeout:  timeout,
+	}
+}
+
+func (pl *proxyListener) Accept() (net.Conn, error) {
+	conn, err := pl.Listener.Accept()
+	if err != nil || !pl.isTrusted(conn.RemoteAddr()) {
+		return conn, err
+	}
+	return &proxyConn{
+		Conn:    conn,
+		timeout: pl.timeout,
+	}, nil
+}
+
+func (pl *proxyListener) isTrusted(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	ip, ok := netip.AddrFromSlice(tcpAddr.IP)
+	if !ok {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, p := range pl.trusted {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyConn reads the PROXY protocol header of a connection from a trusted
+// proxy once it is first used.
+type proxyConn struct {
+	net.Conn
+	timeout time.Duration
+
+	once sync.Once
+	r    *bufio.Reader
+	hdr  *ProxyHeader
+	err  error
+}
+
+// NetConn returns the underlying connection.
+func (pc *proxyConn) NetConn() net.Conn { return pc.Conn }
+
+func (pc *proxyConn) header() (*ProxyHeader, error) {
+	pc.once.Do(func() {
+		if pc.timeout > 0 {
+			_ = pc.Conn.SetReadDeadline(time.Now().Add(pc.timeout))
+			defer func() { _ = pc.Conn.SetReadDeadline(time.Time{}) }()
+		}
+
+		pc.r = bufio.NewReader(pc.Conn)
+		pc.hdr, pc.err = readProxyHeader(pc.r)
+	})
+	return pc.hdr, pc.err
+}
+
+func (pc *proxyConn) Read(b []byte) (int, error) {
+	if _, err := pc.header(); err != nil {
+		return 0, err
+	}
+	if pc.r.Buffered() == 0 {
+		return pc.Conn.Read(b)
+	}
+	return pc.r.Read(b)
+}
+
+// RemoteAddr returns the client address from the PROXY protocol header, or
+// the address of the proxy when there is none.
+func (pc *proxyConn) RemoteAddr() net.Addr {
+	if hdr, err := pc.header(); err == nil && hdr.Source != nil {
+		return hdr.Source
+	}
+	return pc.Conn.RemoteAddr()
+}
+
+// findProxyConn returns the [proxyConn] conn is, or wraps, or nil if there is
+// none.
+func findProxyConn(conn net.Conn) *proxyConn {
+	for {
+		switch c := conn.(type) {
+		case *proxyConn:
+			return c
+		case interface{ NetConn() net.Conn }:
+			conn = c.NetConn()
+		default:
+			return nil
+		}
+	}
+}
+
+func readProxyHeader(r *bufio.Reader) (*ProxyHeader, error) {
+	sig, err := r.Peek(len(proxyV2Sig))
+	if err != nil {
+		return nil, errors.Wrap(err, ErrInvalidProxyHeader)
+	}
+	if bytes.Equal(sig, proxyV2Sig) {
+		return readProxyHeaderV2(r)
+	}
+	if bytes.HasPrefix(sig, []byte("PROXY ")) {
+		return readProxyHeaderV1(r)
+	}
+	return nil, errors.Newf("%w: missing signature", ErrInvalidProxyHeader)
+}
+
+// readProxyHeaderV1 reads a human-readable header of the form
+// "PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n".
+func readProxyHeaderV1(r *bufio.Reader) (*ProxyHeader, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil || len(line) > proxyV1MaxLen || !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errors.Newf("%w: malformed v1 header", ErrInvalidProxyHeader)
+	}
+
+	hdr := ProxyHeader{Version: 1}
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return &hdr, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, errors.Newf("%w: malformed v1 header", ErrInvalidProxyHeader)
+	}
+
+	src, err := parseProxyAddrV1(fields[2], fields[4], fields[1] == "TCP4")
+	if err != nil {
+		return nil, err
+	}
+	dst, err := parseProxyAddrV1(fields[3], fields[5], fields[1] == "TCP4")
+	if err != nil {
+		return nil, err
+	}
+
+	hdr.Source, hdr.Destination = src, dst
+	return &hdr, nil
+}
+
+func parseProxyAddrV1(ip, port string, v4 bool) (*net.TCPAddr, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || addr.Is4() != v4 {
+		return nil, errors.Newf("%w: invalid address %q", ErrInvalidProxyHeader, ip)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, errors.Newf("%w: invalid port %q", ErrInvalidProxyHeader, port)
+	}
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(addr, uint16(p))), nil
+}
+
+// readProxyHeaderV2 reads a binary header, see section 2.2 of the PROXY
+// protocol specification.
+func readProxyHeaderV2(r *bufio.Reader) (*ProxyHeader, error) {
+	var fixed [proxyV2HdrLen]byte
+	if _, err := io.ReadFull(r, fixed[:]); err != nil {
+		return nil, errors.Wrap(err, ErrInvalidProxyHeader)
+	}
+	if fixed[12]>>4 != 2 {
+		return nil, errors.Newf("%w: unsupported version %d", ErrInvalidProxyHeader, fixed[12]>>4)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(fixed[14:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, errors.Wrap(err, ErrInvalidProxyHeader)
+	}
+
+	hdr := ProxyHeader{Version: 2}
+	switch cmd := fixed[12] & 0x0f; cmd {
+	case 0x0: // LOCAL
+		return &hdr, nil
+	case 0x1: // PROXY
+	default:
+		return nil, errors.Newf("%w: unsupported command %d", ErrInvalidProxyHeader, cmd)
+	}
+
+	var ipLen, addrLen int
+	switch fixed[13] {
+	case 0x11: // TCP over IPv4
+		ipLen, addrLen = 4, 12
+	case 0x21: // TCP over IPv6
+		ipLen, addrLen = 16, 36
+	case 0x31: // unix stream
+		addrLen = 216
+	case 0x00: // unspecified
+	default:
+		return nil, errors.Newf("%w: unsupported address family %#x", ErrInvalidProxyHeader, fixed[13])
+	}
+	if len(payload) < addrLen {
+		return nil, errors.Newf("%w: addresses exceed header length", ErrInvalidProxyHeader)
+	}
+
+	// addresses of unix sockets or an unspecified family are ignored, so the
+	// connection is handled as if it originated from the proxy
+	if ipLen != 0 {
+		src, _ := netip.AddrFromSlice(payload[:ipLen])
+		dst, _ := netip.AddrFromSlice(payload[ipLen : 2*ipLen])
+		srcPort := binary.BigEndian.Uint16(payload[2*ipLen:])
+		dstPort := binary.BigEndian.Uint16(payload[2*ipLen+2:])
+
+		hdr.Source = net.TCPAddrFromAddrPort(netip.AddrPortFrom(src, srcPort))
+		hdr.Destination = net.TCPAddrFromAddrPort(netip.AddrPortFrom(dst, dstPort))
+	}
+
+	for tlvs := payload[addrLen:]; len(tlvs) != 0; {
+		if len(tlvs) < 3 {
+			return nil, errors.Newf("%w: truncated tlv", ErrInvalidProxyHeader)
+		}
+		n := int(binary.BigEndian.Uint16(tlvs[1:3]))
+		if len(tlvs) < 3+n {
+			return nil, errors.Newf("%w: truncated tlv", ErrInvalidProxyHeader)
+		}
+		hdr.TLVs = append(hdr.TLVs, ProxyTLV{Type: tlvs[0], Value: tlvs[3 : 3+n]})
+		tlvs = tlvs[3+n:]
+	}
+	return &hdr, nil
+}
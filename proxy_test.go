@@ -0,0 +1,185 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func proxyHeaderV2(cmd, fam byte, addrs []byte, tlvs ...ProxyTLV) []byte {
+	payload := append([]byte(nil), addrs...)
+	for _, tlv := range tlvs {
+		payload = append(payload, tlv.Type)
+		payload = binary.BigEndian.AppendUint16(payload, uint16(len(tlv.Value)))
+		payload = append(payload, tlv.Value...)
+	}
+
+	hdr := append([]byte(nil), proxyV2Sig...)
+	hdr = append(hdr, 0x20|cmd, fam)
+	hdr = binary.BigEndian.AppendUint16(hdr, uint16(len(payload)))
+	return append(hdr, payload...)
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	ipv4 := []byte{192, 0, 2, 1, 192, 0, 2, 2, 0xdc, 0x04, 0x01, 0xbb}
+	sni := ProxyTLV{Type: ProxyTLVAuthority, Value: []byte("example.com")}
+
+	tests := map[string]struct {
+		input   string
+		wantSrc string
+		wantSNI string
+		wantErr bool
+	}{
+		"v1 tcp4": {
+			input:   "PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n",
+			wantSrc: "192.0.2.1:56324",
+		},
+		"v1 tcp6": {
+			input:   "PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n",
+			wantSrc: "[2001:db8::1]:56324",
+		},
+		"v1 unknown": {
+			input: "PROXY UNKNOWN\r\n",
+		},
+		"v1 mismatching family": {
+			input:   "PROXY TCP6 192.0.2.1 192.0.2.2 56324 443\r\n",
+			wantErr: true,
+		},
+		"v1 missing crlf": {
+			input:   "PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\n",
+			wantErr: true,
+		},
+		"v1 too long": {
+			input:   "PROXY UNKNOWN " + strings.Repeat("x", proxyV1MaxLen) + "\r\n",
+			wantErr: true,
+		},
+		"v2 tcp4": {
+			input:   string(proxyHeaderV2(0x1, 0x11, ipv4, sni)),
+			wantSrc: "192.0.2.1:56324",
+			wantSNI: "example.com",
+		},
+		"v2 local": {
+			input: string(proxyHeaderV2(0x0, 0x00, nil)),
+		},
+		"v2 truncated addresses": {
+			input:   string(proxyHeaderV2(0x1, 0x11, ipv4[:6])),
+			wantErr: true,
+		},
+		"v2 truncated tlv": {
+			input:   string(proxyHeaderV2(0x1, 0x11, append(ipv4, ProxyTLVAuthority, 0, 9))),
+			wantErr: true,
+		},
+		"missing": {
+			input:   "GET / HTTP/1.1\r\n\r\n",
+			wantErr: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tc.input + "rest"))
+			hdr, err := readProxyHeader(r)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidProxyHeader)
+				return
+			}
+
+			require.NoError(t, err)
+			if tc.wantSrc == "" {
+				assert.Nil(t, hdr.Source)
+			} else {
+				assert.Equal(t, tc.wantSrc, hdr.Source.String())
+			}
+			assert.Equal(t, tc.wantSNI, hdr.SNI())
+
+			rest, _ := io.ReadAll(r)
+			assert.Equal(t, "rest", string(rest))
+		})
+	}
+}
+
+func TestWithProxyProtocol(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		var srv Server
+		assert.ErrorIs(t, srv.With(WithProxyProtocol()), ErrInvalidTrustedProxy)
+		assert.ErrorIs(t, srv.With(WithProxyProtocol("foo")), ErrInvalidTrustedProxy)
+	})
+
+	run := func(t *testing.T, trusted string) *Server {
+		srv, err := New(
+			WithProxyProtocol(trusted),
+			WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var sni string
+				if hdr := ProxyHeaderFromContext(r.Context()); hdr != nil {
+					sni = hdr.SNI()
+				}
+				_, _ = io.WriteString(w, r.RemoteAddr+" "+sni)
+			})),
+		)
+		require.NoError(t, err)
+		srv.Addr = "127.0.0.1:0"
+
+		done := make(chan error, 1)
+		go func() { done <- srv.Run() }()
+		require.NoError(t, srv.WaitReady(context.Background()))
+
+		t.Cleanup(func() {
+			assert.NoError(t, srv.Close())
+			assert.NoError(t, <-done)
+		})
+		return srv
+	}
+	get := func(t *testing.T, srv *Server, hdr []byte) *http.Response {
+		conn, err := net.Dial("tcp", srv.ListenAddr().String())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+
+		_, err = conn.Write(append(hdr, "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n"...))
+		require.NoError(t, err)
+
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		require.NoError(t, err)
+		return resp
+	}
+	body := func(t *testing.T, resp *http.Response) string {
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return string(b)
+	}
+
+	t.Run("v1", func(t *testing.T) {
+		srv := run(t, "127.0.0.1")
+		resp := get(t, srv, []byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n"))
+		assert.Equal(t, "192.0.2.1:56324 ", body(t, resp))
+	})
+	t.Run("v2", func(t *testing.T) {
+		srv := run(t, "127.0.0.0/8")
+		resp := get(t, srv, proxyHeaderV2(0x1, 0x11,
+			[]byte{192, 0, 2, 1, 192, 0, 2, 2, 0xdc, 0x04, 0x01, 0xbb},
+			ProxyTLV{Type: ProxyTLVAuthority, Value: []byte("example.com")},
+		))
+		assert.Equal(t, "192.0.2.1:56324 example.com", body(t, resp))
+	})
+	t.Run("untrusted", func(t *testing.T) {
+		srv := run(t, "192.0.2.0/24")
+		resp := get(t, srv, nil)
+		assert.True(t, strings.HasPrefix(body(t, resp), "127.0.0.1:"))
+
+		// header is not accepted from untrusted sources
+		resp = get(t, srv, []byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n"))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		_ = resp.Body.Close()
+	})
+}
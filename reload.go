@@ -0,0 +1,246 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/go-pogo/errors"
+)
+
+const ErrUnableToUpdateConfig errors.Msg = "unable to update config"
+
+// UpdatePolicy determines how [Server.UpdateConfig] treats existing
+// connections.
+type UpdatePolicy uint8
+
+const (
+	// UpdateNewConns applies the updated [Config] to new connections only.
+	// Existing connections keep their old settings until they are closed.
+	UpdateNewConns UpdatePolicy = iota
+	// UpdateNextRequest applies the updated [Config] to new connections, and
+	// closes existing connections once they are idle. Clients thus pick up
+	// the new settings with their next request on a new connection.
+	UpdateNextRequest
+)
+
+// generation is an [http.Server] which serves an acceptor of each of the
+// [Server]'s [sharedListener]s. The [Server]'s internal [http.Server] is the
+// first generation, each [Server.UpdateConfig] starts a new one.
+type generation struct {
+	*http.Server
+	acceptors []net.Listener
+	// serving is the number of acceptors that are still being served
+	serving int
+	// conns is the number of open connections, only tracked for generations
+	// started by UpdateConfig
+	conns atomic.Int64
+	// retired indicates the generation no longer accepts new connections
+	retired atomic.Bool
+}
+
+// UpdateConfig applies cfg to the [Server] without restarting it. When the
+// [Server] is serving, a new internal [http.Server] with the updated timeouts
+// and header limits takes over accepting connections from the [Server]'s
+// listeners. Connection limits apply to new connections immediately.
+// Depending on policy, existing connections either keep their old settings,
+// or are closed once they are idle.
+// When the [Server] is not started, cfg is applied the next time it starts.
+// The update is reported to the [Server]'s [Logger] when it implements
+// [ConfigLogger].
+//
+// An error is returned when cfg is not valid, see [Config.Validate]. An
+// [InvalidStateError] containing a [ErrUnableToUpdateConfig] error is returned
+// when the [Server] is started but not yet ready, or is shutting down.
+func (srv *Server) UpdateConfig(cfg Config, policy UpdatePolicy) error {
+	if cfg.IsZero() {
+		cfg = defaultConfig
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	srv.mut.Lock()
+	switch state := srv.state; state {
+	case StateStarted, StatePaused:
+		if srv.shared != nil && srv.serveErr == nil {
+			break
+		}
+		fallthrough
+	case StateClosing:
+		srv.mut.Unlock()
+		return errors.WithStack(&InvalidStateError{
+			Err:   ErrUnableToUpdateConfig,
+			State: state,
+		})
+	default:
+		srv.Config = cfg
+		srv.mut.Unlock()
+		return nil
+	}
+
+	old := srv.Config
+	srv.Config = cfg
+	srv.limiter.set(&cfg)
+
+	prev := srv.gens[len(srv.gens)-1]
+	gen := srv.serveGeneration(srv.newGenerationServer(cfg), nil)
+	prev.retired.Store(true)
+
+	log, name := srv.log, srv.name
+	srv.mut.Unlock()
+
+	for _, a := range prev.acceptors {
+		_ = a.Close()
+	}
+	if policy == UpdateNextRequest {
+		for _, g := range srv.generations() {
+			if g != gen {
+				g.SetKeepAlivesEnabled(false)
+			}
+		}
+	}
+
+	if l, ok := log.(ConfigLogger); ok {
+		l.LogServerConfigUpdate(name, old, cfg)
+	}
+	return nil
+}
+
+// UpdateConfigOnSignal blocks and calls load followed by
+// [Server.UpdateConfig] each time one of the provided signals is received,
+// until ctx is done. It listens for [syscall.SIGHUP] when no signals are
+// provided. Failed updates are logged using the [Server]'s [ConfigLogger],
+// if available.
+func (srv *Server) UpdateConfigOnSignal(ctx context.Context, load func() (Config, error), policy UpdatePolicy, sig ...os.Signal) error {
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig...)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ch:
+			cfg, err := load()
+			if err == nil {
+				err = srv.UpdateConfig(cfg, policy)
+			}
+			if err != nil {
+				if l, ok := srv.logger().(ConfigLogger); ok {
+					l.LogServerConfigUpdateError(srv.Name(), errors.Wrap(err, ErrUnableToUpdateConfig))
+				}
+			}
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// newGenerationServer returns a new [http.Server] which is configured the
+// same way as the [Server]'s internal [http.Server], except for cfg. The
+// [Server]'s lock must be held when calling newGenerationServer.
+func (srv *Server) newGenerationServer(cfg Config) *http.Server {
+	s := cloneServer(&srv.httpServer)
+	s.Addr = srv.httpServer.Addr
+	s.Handler = srv.httpServer.Handler
+	cfg.ApplyTo(&s)
+	return &s
+}
+
+// trackConns wraps the [http.ConnState] of the generation's [http.Server] to
+// keep track of its open connections, so it can be removed once it is
+// retired and all of its connections are closed.
+func (gen *generation) trackConns(srv *Server) {
+	next := gen.ConnState
+	gen.ConnState = func(conn net.Conn, state http.ConnState) {
+		if next != nil {
+			next(conn, state)
+		}
+
+		switch state {
+		case http.StateNew:
+			gen.conns.Add(1)
+		case http.StateHijacked, http.StateClosed:
+			if gen.conns.Add(-1) == 0 && gen.retired.Load() {
+				srv.mut.Lock()
+				srv.pruneGeneration(gen)
+				srv.mut.Unlock()
+			}
+		}
+	}
+}
+
+// pruneGeneration removes gen when it is retired and all of its acceptors
+// and connections are closed. The internal [http.Server] is never removed.
+// The [Server]'s lock must be held when calling pruneGeneration.
+func (srv *Server) pruneGeneration(gen *generation) {
+	if gen.Server == &srv.httpServer || !gen.retired.Load() || gen.serving != 0 || gen.conns.Load() != 0 {
+		return
+	}
+	for i, g := range srv.gens {
+		if g == gen {
+			srv.gens = append(srv.gens[:i:i], srv.gens[i+1:]...)
+			return
+		}
+	}
+}
+
+// generations returns a copy of the [Server]'s current generations.
+func (srv *Server) generations() []*generation {
+	srv.mut.RLock()
+	defer srv.mut.RUnlock()
+	return append([]*generation(nil), srv.gens...)
+}
+
+// servers returns the [http.Server]s of all generations, or only the internal
+// [http.Server] when the [Server] is not serving. The [Server]'s lock must be
+// held when calling servers.
+func (srv *Server) servers() []*http.Server {
+	if len(srv.gens) == 0 {
+		return []*http.Server{&srv.httpServer}
+	}
+
+	res := make([]*http.Server, 0, len(srv.gens))
+	for _, g := range srv.gens {
+		res = append(res, g.Server)
+	}
+	return res
+}
+
+// eachServer calls fn for each of the servers in parallel, and returns all
+// errors.
+func eachServer(servers []*http.Server, fn func(s *http.Server) error) error {
+	if len(servers) == 1 {
+		return fn(servers[0])
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(servers))
+	for i, s := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fn(s)
+		}()
+	}
+	wg.Wait()
+
+	var err error
+	for _, e := range errs {
+		err = errors.Append(err, e)
+	}
+	return err
+}
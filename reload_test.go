@@ -0,0 +1,114 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_UpdateConfig(t *testing.T) {
+	t.Run("not started", func(t *testing.T) {
+		var srv Server
+		cfg := Config{ReadTimeout: time.Second}
+		require.NoError(t, srv.UpdateConfig(cfg, UpdateNewConns))
+		assert.Equal(t, cfg, srv.Config)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var srv Server
+		err := srv.UpdateConfig(Config{ReadTimeout: -1}, UpdateNewConns)
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		assert.True(t, srv.Config.IsZero())
+	})
+
+	// a request with a header larger than 1KiB + the 4KiB which is added by
+	// http.Server to MaxHeaderBytes
+	req := "GET / HTTP/1.1\r\nHost: localhost\r\nX-Large: " + strings.Repeat("x", 8<<10) + "\r\n\r\n"
+	send := func(t *testing.T, conn net.Conn, r *bufio.Reader) int {
+		_, err := io.WriteString(conn, req)
+		require.NoError(t, err)
+		resp, err := http.ReadResponse(r, nil)
+		require.NoError(t, err)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	run := func(t *testing.T) (*Server, chan error) {
+		srv, err := New(WithHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+		require.NoError(t, err)
+		srv.Addr = "127.0.0.1:0"
+
+		done := make(chan error, 1)
+		go func() { done <- srv.Run() }()
+		require.NoError(t, srv.WaitReady(context.Background()))
+		return srv, done
+	}
+	dial := func(t *testing.T, srv *Server) (net.Conn, *bufio.Reader) {
+		conn, err := net.Dial("tcp", srv.ListenAddr().String())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+		return conn, bufio.NewReader(conn)
+	}
+
+	update := defaultConfig
+	update.MaxHeaderBytes = 1 << 10
+
+	t.Run("new conns", func(t *testing.T) {
+		srv, done := run(t)
+		conn, r := dial(t, srv)
+		assert.Equal(t, http.StatusOK, send(t, conn, r))
+
+		require.NoError(t, srv.UpdateConfig(update, UpdateNewConns))
+		assert.Equal(t, update, srv.Config)
+		assert.Equal(t, StateStarted, srv.State())
+
+		// existing connection keeps its old settings
+		assert.Equal(t, http.StatusOK, send(t, conn, r))
+
+		conn2, r2 := dial(t, srv)
+		assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, send(t, conn2, r2))
+
+		require.NoError(t, srv.Shutdown(context.Background()))
+		assert.NoError(t, <-done)
+		assert.Equal(t, StateClosed, srv.State())
+		assert.Nil(t, srv.gens)
+	})
+	t.Run("next request", func(t *testing.T) {
+		srv, done := run(t)
+		conn, r := dial(t, srv)
+		assert.Equal(t, http.StatusOK, send(t, conn, r))
+
+		require.NoError(t, srv.UpdateConfig(update, UpdateNextRequest))
+
+		// idle connection is closed
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, err := r.ReadByte()
+		assert.ErrorIs(t, err, io.EOF)
+
+		conn2, r2 := dial(t, srv)
+		assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, send(t, conn2, r2))
+
+		require.NoError(t, srv.Shutdown(context.Background()))
+		assert.NoError(t, <-done)
+	})
+	t.Run("closing", func(t *testing.T) {
+		srv, err := New(OnShutdown(func(context.Context, StateEvent) error {
+			return nil
+		}))
+		require.NoError(t, err)
+		srv.state = StateClosing
+		assert.ErrorIs(t, srv.UpdateConfig(update, UpdateNewConns), ErrUnableToUpdateConfig)
+	})
+}
@@ -8,16 +8,20 @@ package serv
 
 import "net/http"
 
-func (srv *Server) resetServer() {
-	srv.httpServer = http.Server{
-		DisableGeneralOptionsHandler: srv.DisableGeneralOptionsHandler,
-		TLSConfig:                    srv.TLSConfig,
-		TLSNextProto:                 srv.TLSNextProto,
-		ConnState:                    srv.ConnState,
-		ErrorLog:                     srv.ErrorLog,
-		BaseContext:                  srv.BaseContext,
-		ConnContext:                  srv.ConnContext,
-		HTTP2:                        srv.HTTP2,
-		Protocols:                    srv.Protocols,
+func (srv *Server) resetServer() { srv.httpServer = cloneServer(&srv.httpServer) }
+
+// cloneServer returns a new [http.Server] with the fields of s that are not
+// set by [Server.start] or [Config.ApplyTo].
+func cloneServer(s *http.Server) http.Server {
+	return http.Server{
+		DisableGeneralOptionsHandler: s.DisableGeneralOptionsHandler,
+		TLSConfig:                    s.TLSConfig,
+		TLSNextProto:                 s.TLSNextProto,
+		ConnState:                    s.ConnState,
+		ErrorLog:                     s.ErrorLog,
+		BaseContext:                  s.BaseContext,
+		ConnContext:                  s.ConnContext,
+		HTTP2:                        s.HTTP2,
+		Protocols:                    s.Protocols,
 	}
 }
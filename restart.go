@@ -0,0 +1,158 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+
+	"github.com/go-pogo/errors"
+)
+
+const ErrUnableToRestart errors.Msg = "unable to restart server"
+
+type restartSignal struct {
+	// stopped is closed by the serving goroutine once it has stopped serving
+	// all listeners
+	stopped chan struct{}
+	// started is closed once the restart is complete
+	started chan struct{}
+	// ok indicates if the serving goroutine should continue serving
+	ok bool
+}
+
+// Restart gracefully shuts down the [Server] and starts it again, while
+// keeping its listeners open. Incoming connections are queued by the
+// listeners until the [Server] is started again, so clients are never
+// refused. Any connections that remain open after [Config.ShutdownTimeout]
+// are forcefully closed, see [Server.RunContext]. Restart blocks until the
+// handlers of all closed connections have returned.
+//
+// The provided [Option]s, e.g. a *[Config], are applied before the [Server]
+// starts again. Because the listeners are kept open, changes to [Server.Addr]
+// or additional [Endpoint]s have no effect until the [Server] is stopped and
+// run again.
+//
+// The goroutine that is blocked by [Server.Run] (or one of the other serve
+// methods) keeps blocking while the [Server] restarts. The [State] of the
+// [Server] remains [StateClosing] until it is started again, so [OnClosed]
+// hooks are not called. [Server.Shutdown] and [Server.Close] wait for the
+// restart to complete before stopping the [Server], while [Server.Run] and
+// the other serve methods are rejected.
+// An [InvalidStateError] containing a [ErrUnableToRestart] error is returned
+// when the [Server] is not started, is not yet serving, or is already
+// restarting.
+func (srv *Server) Restart(ctx context.Context, opts ...Option) error {
+	srv.mut.Lock()
+	if state := srv.state; !state.isRunning() || len(srv.listeners) == 0 || srv.restarting != nil {
+		srv.mut.Unlock()
+		return errors.WithStack(&InvalidStateError{
+			Err:   ErrUnableToRestart,
+			State: state,
+		})
+	}
+
+	rs := &restartSignal{
+		stopped: make(chan struct{}),
+		started: make(chan struct{}),
+	}
+	srv.restarting = rs
+	// a new ready channel is closed once the server is started again
+	srv.sig.ready = make(chan struct{})
+	done := srv.sig.done
+	srv.mut.Unlock()
+
+	defer func() {
+		srv.mut.Lock()
+		srv.restarting = nil
+		srv.mut.Unlock()
+		close(rs.started)
+	}()
+
+	err := srv.shutdownServers(ctx, true)
+	if errors.Is(err, ErrUnableToShutdown) {
+		// server is closed in the meantime
+		return errors.Wrap(err, ErrUnableToRestart)
+	}
+
+	// wait until the previous serving goroutines and connections have
+	// stopped, so the internal http.Server can safely be reset
+	select {
+	case <-rs.stopped:
+	case <-done:
+		// server stopped serving due to an error
+		return errors.Append(err, errors.New(ErrUnableToRestart))
+	}
+	srv.waitConnsClosed()
+
+	if len(opts) != 0 {
+		srv.mut.Lock()
+		if e := srv.with(opts); e != nil {
+			err = errors.Append(err, errors.Wrap(e, ErrApplyOptions))
+		}
+		srv.mut.Unlock()
+	}
+
+	if e := srv.startRestart(rs); e != nil {
+		// complete the shutdown, so the server does not remain closing
+		return errors.Append(err, errors.Append(e, srv.close(ctx)))
+	}
+
+	rs.ok = true
+	return err
+}
+
+// awaitRestart is called by the serving goroutine after it has stopped
+// serving. It blocks until any pending restart is complete, and returns true
+// when it should continue serving.
+func (srv *Server) awaitRestart() bool {
+	srv.mut.RLock()
+	rs := srv.restarting
+	srv.mut.RUnlock()
+	if rs == nil {
+		return false
+	}
+
+	close(rs.stopped)
+	<-rs.started
+	return rs.ok
+}
+
+// shutdownRestarted is similar to shutdown, but first waits for any pending
+// restart to complete, so the [Server] cannot continue serving after it is
+// shut down. It is preceded by the drain period, see [Server.Drain].
+func (srv *Server) shutdownRestarted(ctx context.Context, force bool) error {
+	srv.waitRestarted()
+	drainErr := srv.drainBeforeShutdown(ctx)
+	err := srv.afterRestart(ErrUnableToShutdown, func() error {
+		return srv.shutdown(ctx, force)
+	})
+	return errors.Append(drainErr, err)
+}
+
+// afterRestart calls fn, and calls it again once a pending restart is
+// complete when fn fails with an error containing msg, because of the
+// [State] of the [Server] while restarting.
+func (srv *Server) afterRestart(msg errors.Msg, fn func() error) error {
+	for {
+		err := fn()
+		if !errors.Is(err, msg) || !srv.waitRestarted() {
+			return err
+		}
+	}
+}
+
+// waitRestarted blocks until any pending restart is complete. It returns
+// false when there is no pending restart.
+func (srv *Server) waitRestarted() bool {
+	srv.mut.RLock()
+	rs := srv.restarting
+	srv.mut.RUnlock()
+	if rs == nil {
+		return false
+	}
+
+	<-rs.started
+	return true
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Restart(t *testing.T) {
+	t.Run("not started", func(t *testing.T) {
+		var srv Server
+		assert.ErrorIs(t, srv.Restart(context.Background()), ErrUnableToRestart)
+	})
+
+	textHandler := func(text string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = io.WriteString(w, text)
+		})
+	}
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	get := func(t *testing.T, url string) string {
+		resp, err := client.Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	t.Run("keep listeners", func(t *testing.T) {
+		var starts int
+		srv, err := New(
+			WithName("foo"),
+			WithHandler(textHandler("first")),
+			OnStart(func(context.Context, StateEvent) error {
+				starts++
+				return nil
+			}),
+		)
+		require.NoError(t, err)
+		srv.Addr = "127.0.0.1:0"
+
+		done := make(chan error, 1)
+		go func() { done <- srv.Run() }()
+		require.NoError(t, srv.WaitReady(context.Background()))
+
+		addr := srv.ListenAddr().String()
+		assert.Equal(t, "first", get(t, "http://"+addr))
+
+		require.NoError(t, srv.Restart(context.Background(), WithHandler(textHandler("second"))))
+		require.NoError(t, srv.WaitReady(context.Background()))
+		assert.Equal(t, StateStarted, srv.State())
+		assert.Equal(t, addr, srv.ListenAddr().String())
+		assert.Equal(t, "second", get(t, "http://"+addr))
+		assert.Equal(t, 2, starts)
+
+		select {
+		case err = <-done:
+			t.Fatalf("Run returned during restart: %v", err)
+		default:
+		}
+
+		require.NoError(t, srv.Shutdown(context.Background()))
+		assert.NoError(t, <-done)
+		assert.Equal(t, StateClosed, srv.State())
+		assert.ErrorIs(t, srv.Restart(context.Background()), ErrUnableToRestart)
+	})
+	t.Run("shutdown while restarting", func(t *testing.T) {
+		handling := make(chan struct{})
+		release := make(chan struct{})
+		srv, err := New(WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			close(handling)
+			<-release
+			_, _ = io.WriteString(w, "done")
+		})))
+		require.NoError(t, err)
+		srv.Addr = "127.0.0.1:0"
+
+		done := make(chan error, 1)
+		go func() { done <- srv.Run() }()
+		require.NoError(t, srv.WaitReady(context.Background()))
+
+		go func() {
+			if resp, err := client.Get("http://" + srv.ListenAddr().String()); err == nil {
+				_ = resp.Body.Close()
+			}
+		}()
+		<-handling
+
+		restarted := make(chan error, 1)
+		go func() { restarted <- srv.Restart(context.Background()) }()
+		require.Eventually(t, func() bool {
+			return srv.State() == StateClosing
+		}, time.Second, time.Millisecond)
+
+		assert.ErrorIs(t, srv.Run(), ErrUnableToStart, "should not start while restarting")
+
+		shutdown := make(chan error, 1)
+		go func() { shutdown <- srv.Shutdown(context.Background()) }()
+
+		select {
+		case err = <-shutdown:
+			t.Fatalf("Shutdown returned during restart: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+		assert.NoError(t, <-restarted)
+		assert.NoError(t, <-shutdown)
+		assert.NoError(t, <-done)
+		assert.Equal(t, StateClosed, srv.State())
+	})
+	t.Run("run context", func(t *testing.T) {
+		srv := Server{Addr: "127.0.0.1:0"}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan error, 1)
+		go func() { done <- srv.RunContext(ctx) }()
+		require.NoError(t, srv.WaitReady(context.Background()))
+		require.NoError(t, srv.Restart(context.Background(), &Config{ShutdownTimeout: time.Second}))
+		assert.Equal(t, Config{ShutdownTimeout: time.Second}, srv.Config)
+
+		cancel()
+		assert.NoError(t, <-done)
+		assert.Equal(t, StateClosed, srv.State())
+	})
+}
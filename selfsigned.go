@@ -0,0 +1,214 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-pogo/easytls"
+	"github.com/go-pogo/errors"
+)
+
+const ErrSelfSignedTLS errors.Msg = "unable to create self-signed certificate"
+
+const (
+	selfSignedCACert   = "ca.pem"
+	selfSignedCAKey    = "ca-key.pem"
+	selfSignedLeafCert = "cert.pem"
+	selfSignedLeafKey  = "key.pem"
+
+	// selfSignedMinValidity is the minimum remaining validity of a cached
+	// leaf certificate for it to be reused
+	selfSignedMinValidity = 7 * 24 * time.Hour
+)
+
+var (
+	_ Option                       = (*SelfSignedTLS)(nil)
+	_ easytls.Option               = (*SelfSignedTLS)(nil)
+	_ easytls.TLSCertificateLoader = (*SelfSignedTLS)(nil)
+)
+
+// SelfSignedTLS generates a CA and a leaf certificate which is signed by this
+// CA, so a [Server] can be served using TLS during development. It implements
+// [Option], [easytls.Option] and [easytls.TLSCertificateLoader], and can thus
+// be provided to [New], [Server.With] or [WithTLSConfig].
+// It should not be used in production.
+type SelfSignedTLS struct {
+	// Hosts are the host names and/or IP addresses the leaf certificate is
+	// valid for. It defaults to "localhost", "127.0.0.1" and "::1".
+	Hosts []string
+	// CacheDir optionally specifies a directory in which the generated CA
+	// and leaf certificate are stored. A previously stored CA is reused, so
+	// it only needs to be trusted once by e.g. a browser. A stored leaf
+	// certificate is reused when it is still valid for all Hosts.
+	CacheDir string
+}
+
+// WithSelfSignedTLS generates an in-memory CA and leaf certificate for the
+// provided hosts, and adds it to the [Server]'s internal
+// [http.Server.TLSConfig], so [Server.Run] serves using TLS.
+// See [SelfSignedTLS] for additional information.
+func WithSelfSignedTLS(hosts ...string) Option { return SelfSignedTLS{Hosts: hosts} }
+
+func (s SelfSignedTLS) apply(srv *Server) error {
+	if srv.TLSConfig == nil {
+		srv.TLSConfig = easytls.DefaultTLSConfig()
+	}
+	return s.ApplyTo(srv.TLSConfig, easytls.TargetServer)
+}
+
+// ApplyTo generates a certificate using [SelfSignedTLS.LoadTLSCertificate]
+// and adds it to the provided [tls.Config]. This method implements the
+// [easytls.Option] interface.
+func (s SelfSignedTLS) ApplyTo(conf *tls.Config, _ easytls.Target) error {
+	if conf == nil {
+		return nil
+	}
+
+	cert, err := s.LoadTLSCertificate()
+	if err != nil {
+		return err
+	}
+	conf.Certificates = append(conf.Certificates, *cert)
+	return nil
+}
+
+// LoadTLSCertificate returns a leaf certificate which contains the CA in its
+// chain. New certificates are generated on each call, unless they can be
+// reused from CacheDir. This method implements the
+// [easytls.TLSCertificateLoader] interface.
+func (s SelfSignedTLS) LoadTLSCertificate() (*tls.Certificate, error) {
+	hosts := s.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{"localhost", "127.0.0.1", "::1"}
+	}
+
+	ca, caKey, err := s.loadOrCreate(selfSignedCACert, selfSignedCAKey, nil, func() (*x509.Certificate, crypto.Signer, error) {
+		return createCertificate(easytls.CACertificate(pkix.Name{
+			Organization: []string{"serv"},
+			CommonName:   "serv development CA",
+		}), nil, nil)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, ErrSelfSignedTLS)
+	}
+
+	reuse := func(leaf *x509.Certificate) bool {
+		if leaf.CheckSignatureFrom(ca) != nil || time.Until(leaf.NotAfter) < selfSignedMinValidity {
+			return false
+		}
+		for _, h := range hosts {
+			if leaf.VerifyHostname(h) != nil {
+				return false
+			}
+		}
+		return true
+	}
+
+	leaf, key, err := s.loadOrCreate(selfSignedLeafCert, selfSignedLeafKey, reuse, func() (*x509.Certificate, crypto.Signer, error) {
+		tmpl := easytls.ServerCertificate(hosts...)
+		tmpl.Subject = pkix.Name{
+			Organization: []string{"serv"},
+			CommonName:   hosts[0],
+		}
+		return createCertificate(tmpl, ca, caKey)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, ErrSelfSignedTLS)
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{leaf.Raw, ca.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// loadOrCreate loads the certificate and key pair with the provided file names
+// from CacheDir. When they cannot be loaded, or reuse reports they should not
+// be reused, a new pair is created and stored in CacheDir.
+func (s SelfSignedTLS) loadOrCreate(certName, keyName string, reuse func(*x509.Certificate) bool, create func() (*x509.Certificate, crypto.Signer, error)) (*x509.Certificate, crypto.Signer, error) {
+	if s.CacheDir == "" {
+		return create()
+	}
+
+	certFile := filepath.Join(s.CacheDir, certName)
+	keyFile := filepath.Join(s.CacheDir, keyName)
+	if pair, err := tls.LoadX509KeyPair(certFile, keyFile); err == nil {
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		key, ok := pair.PrivateKey.(crypto.Signer)
+		if err == nil && ok && time.Now().Before(cert.NotAfter) && (reuse == nil || reuse(cert)) {
+			return cert, key, nil
+		}
+	}
+
+	cert, key, err := create()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = writeKeyPair(certFile, keyFile, cert, key); err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// createCertificate creates a new certificate from tmpl, which is signed by
+// parent and parentKey. The certificate is self-signed when parent is nil.
+func createCertificate(tmpl, parent *x509.Certificate, parentKey crypto.Signer) (*x509.Certificate, crypto.Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	tmpl.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), parentKey)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return cert, key, nil
+}
+
+func writeKeyPair(certFile, keyFile string, cert *x509.Certificate, key crypto.Signer) error {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err = os.MkdirAll(filepath.Dir(certFile), 0700); err != nil {
+		return errors.WithStack(err)
+	}
+
+	const perm fs.FileMode = 0600
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), perm); err != nil {
+		return errors.WithStack(err)
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), perm); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-pogo/easytls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfSignedTLS_LoadTLSCertificate(t *testing.T) {
+	t.Run("default hosts", func(t *testing.T) {
+		cert, err := SelfSignedTLS{}.LoadTLSCertificate()
+		require.NoError(t, err)
+		require.Len(t, cert.Certificate, 2)
+
+		assert.NoError(t, cert.Leaf.VerifyHostname("localhost"))
+		assert.NoError(t, cert.Leaf.VerifyHostname("127.0.0.1"))
+		assert.NoError(t, cert.Leaf.VerifyHostname("::1"))
+
+		ca, err := x509.ParseCertificate(cert.Certificate[1])
+		require.NoError(t, err)
+		assert.True(t, ca.IsCA)
+		assert.NoError(t, cert.Leaf.CheckSignatureFrom(ca))
+	})
+	t.Run("cache", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "certs")
+		first, err := SelfSignedTLS{CacheDir: dir}.LoadTLSCertificate()
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(dir, selfSignedCACert))
+		assert.FileExists(t, filepath.Join(dir, selfSignedLeafKey))
+
+		second, err := SelfSignedTLS{CacheDir: dir}.LoadTLSCertificate()
+		require.NoError(t, err)
+		assert.Equal(t, first.Certificate, second.Certificate)
+
+		// the CA is reused for a new leaf certificate with other hosts
+		third, err := SelfSignedTLS{Hosts: []string{"example.test"}, CacheDir: dir}.LoadTLSCertificate()
+		require.NoError(t, err)
+		assert.Equal(t, first.Certificate[1], third.Certificate[1])
+		assert.NotEqual(t, first.Certificate[0], third.Certificate[0])
+		assert.NoError(t, third.Leaf.VerifyHostname("example.test"))
+	})
+}
+
+func TestWithSelfSignedTLS(t *testing.T) {
+	t.Run("easytls", func(t *testing.T) {
+		conf := easytls.DefaultTLSConfig()
+		var srv Server
+		require.NoError(t, srv.With(WithTLSConfig(conf, SelfSignedTLS{})))
+		assert.Len(t, srv.TLSConfig.Certificates, 1)
+	})
+
+	srv, err := New(
+		WithSelfSignedTLS("127.0.0.1"),
+		WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = io.WriteString(w, "secure")
+		})),
+	)
+	require.NoError(t, err)
+	require.True(t, ShouldUseTLS(srv.TLSConfig))
+	srv.Addr = "127.0.0.1:0"
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Run() }()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	ca, err := x509.ParseCertificate(srv.TLSConfig.Certificates[0].Certificate[1])
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	client := http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: pool},
+	}}
+	resp, err := client.Get("https://" + srv.ListenAddr().String())
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	client.CloseIdleConnections()
+	assert.Equal(t, "secure", string(body))
+
+	require.NoError(t, srv.Close())
+	assert.NoError(t, <-done)
+}
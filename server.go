@@ -7,9 +7,13 @@ package serv
 import (
 	"context"
 	"crypto/tls"
+	"io/fs"
 	"net"
 	"net/http"
+	"net/netip"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-pogo/errors"
@@ -36,9 +40,12 @@ type Server struct {
 	// Changes to [Config] after starting the [Server] will not be applied
 	// until after the [Server] is restarted.
 	Config Config
-	// Addr optionally specifies the TCP address for the server to listen on.
+	// Addr optionally specifies the address for the server to listen on, in
+	// the string form of an [Address]. This allows listening on a unix domain
+	// socket using "unix:/path/to/socket".
 	// Changing Addr after starting the [Server] will not affect it until after
-	// the [Server] is restarted.
+	// the [Server] is stopped and run again. [Server.Restart] keeps the
+	// [Server]'s listeners open and thus does not apply a changed Addr.
 	// See [net.Dial] for details of the address format.
 	// See [http.Server] for additional information.
 	Addr string
@@ -47,10 +54,60 @@ type Server struct {
 	// is restarted.
 	Handler http.Handler
 
-	mut   sync.RWMutex
-	log   Logger
-	name  string
-	state State
+	mut    sync.RWMutex
+	log    Logger
+	name   string
+	state  State
+	event  StateEvent
+	hooks  []Hook
+	subMut sync.Mutex
+	subs   []*subscriber
+
+	stats              connStats
+	limiter            connLimiter
+	started            time.Time
+	draining           atomic.Bool
+	paused             atomic.Bool
+	shuttingDown       chan struct{}
+	connState          func(net.Conn, http.ConnState)
+	connStateWrapped   bool
+	connContext        func(context.Context, net.Conn) context.Context
+	connContextWrapped bool
+	proxyTrusted       []netip.Prefix
+	endpoints          []Endpoint
+	inherited          []net.Listener
+	released           bool
+	listeners          []net.Listener
+	socketMode         fs.FileMode
+	shared             []boundListener
+	gens               []*generation
+	serving            sync.WaitGroup
+	serveErr           error
+	restarting         *restartSignal
+	sig                *runSignals
+	nextSig            *runSignals
+}
+
+// runSignals contains the signals of a single run of the [Server], from when
+// it is started until it has stopped serving.
+type runSignals struct {
+	// ready is closed once the listeners are served, it is replaced when the
+	// [Server] is restarted
+	ready chan struct{}
+	// done is closed once the [Server] has stopped serving and its listeners
+	// are closed
+	done chan struct{}
+	// err is the reason the [Server] stopped serving
+	err error
+	// serving indicates the listeners of the run are served, see beginServe
+	serving bool
+}
+
+func newRunSignals() *runSignals {
+	return &runSignals{
+		ready: make(chan struct{}),
+		done:  make(chan struct{}),
+	}
 }
 
 // New creates a new [Server] with a default [Config].
@@ -66,7 +123,7 @@ func New(opts ...Option) (*Server, error) {
 // [InvalidStateError] containing a [ErrAlreadyStarted] error when the
 // server has already started.
 func (srv *Server) With(opts ...Option) error {
-	if state := srv.State(); state == StateStarted {
+	if state := srv.State(); state.isRunning() {
 		return errors.WithStack(&InvalidStateError{
 			Err:   ErrAlreadyStarted,
 			State: state,
@@ -108,19 +165,59 @@ func (srv *Server) State() State {
 	return srv.state
 }
 
+func (srv *Server) logger() Logger {
+	srv.mut.RLock()
+	defer srv.mut.RUnlock()
+	if srv.log == nil {
+		return NopLogger()
+	}
+	return srv.log
+}
+
+// start starts the [Server]. When the [Server] has stopped, it first waits
+// until the connections of the previous run are closed, as they may still
+// access the internal [http.Server] which is reset by startRestart.
 func (srv *Server) start() error {
-	srv.mut.Lock()
-	defer srv.mut.Unlock()
+	if state := srv.State(); state == StateClosed || state == StateErrored {
+		srv.waitConnsClosed()
+	}
+	return srv.startRestart(nil)
+}
 
-	if srv.state == StateStarted || srv.state == StateClosing {
+// startRestart starts the [Server]. When rs is not nil, the [Server] is
+// started again by [Server.Restart] and its state is still [StateClosing].
+// Otherwise, starting is rejected while a restart is pending.
+func (srv *Server) startRestart(rs *restartSignal) error {
+	srv.mut.Lock()
+	if state := srv.state; state.isRunning() || (state == StateClosing && rs == nil) || srv.restarting != rs {
+		srv.mut.Unlock()
 		return errors.WithStack(&InvalidStateError{
 			Err:   ErrUnableToStart,
-			State: srv.state,
+			State: state,
 		})
 	}
-	if srv.state == StateClosed {
+	// protocols which are not supported by the Go version would be ignored
+	// by the internal http.Server
+	if err := srv.Config.validateProtocols(); err != nil {
+		srv.mut.Unlock()
+		return errors.Wrap(err, ErrInvalidConfig)
+	}
+	if srv.state == StateClosed || rs != nil {
 		srv.resetServer()
 	}
+	srv.shuttingDown = renewChan(srv.shuttingDown)
+	srv.trackConns()
+	srv.wrapConnContext()
+
+	if rs == nil {
+		// waiters of Ready and WaitReady may already wait for this run
+		if srv.sig = srv.nextSig; srv.sig == nil {
+			srv.sig = newRunSignals()
+		}
+		srv.nextSig = nil
+	}
+	srv.draining.Store(false)
+	srv.paused.Store(false)
 
 	if srv.log == nil {
 		srv.log = NopLogger()
@@ -135,16 +232,41 @@ func (srv *Server) start() error {
 	}
 
 	srv.Config.ApplyTo(&srv.httpServer)
+	srv.limiter.set(&srv.Config)
 	srv.httpServer.Addr = srv.Addr
 	srv.httpServer.Handler = http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		if srv.paused.Load() && !isHealthHandler(handler, req) {
+			servePaused(wri)
+			return
+		}
+
+		srv.stats.requests.Add(1)
+		defer srv.stats.requests.Add(-1)
+
+		if srv.draining.Load() {
+			wri.Header().Set("Connection", "close")
+		}
+
 		req, info := requestWithInfo(req)
 		if srv.name != "" {
 			info.ServerName = srv.name
 		}
+		if req.TLS != nil {
+			info.ClientIdentity = requestClientIdentity(req)
+		}
+		defer srv.untrackHijacked(req.Context())
 		handler.ServeHTTP(wri, req)
 	})
 
-	srv.state = StateStarted
+	event, ok := srv.setState(StateStarted, nil)
+	srv.started = event.Time
+	log, name, cfg := srv.log, srv.name, srv.Config
+	srv.mut.Unlock()
+	srv.emit(event, ok)
+
+	if l, ok := log.(ConfigLogger); ok {
+		l.LogServerConfig(name, cfg)
+	}
 	return nil
 }
 
@@ -153,13 +275,7 @@ func (srv *Server) Serve(l net.Listener) error {
 	if err := srv.start(); err != nil {
 		return err
 	}
-
-	srv.log.LogServerStart(srv.name, srv.Addr)
-	err := srv.httpServer.Serve(l)
-	if !srv.isClosed(err) {
-		err = errors.WithStack(err)
-	}
-	return err
+	return srv.serve([]boundListener{{Listener: l}})
 }
 
 // ListenAndServe is a wrapper for [http.Server.ListenAndServe].
@@ -167,13 +283,7 @@ func (srv *Server) ListenAndServe() error {
 	if err := srv.start(); err != nil {
 		return err
 	}
-
-	srv.log.LogServerStart(srv.name, srv.Addr)
-	err := srv.httpServer.ListenAndServe()
-	if !srv.isClosed(err) {
-		err = errors.WithStack(err)
-	}
-	return err
+	return srv.listenAndServe([]Endpoint{{Addr: srv.Addr}}, "", "")
 }
 
 // ServeTLS is a wrapper for [http.Server.ServeTLS].
@@ -181,13 +291,12 @@ func (srv *Server) ServeTLS(l net.Listener, certFile, keyFile string) error {
 	if err := srv.start(); err != nil {
 		return err
 	}
-
-	srv.log.LogServerStartTLS(srv.name, srv.Addr, certFile, keyFile)
-	err := srv.httpServer.ServeTLS(l, certFile, keyFile)
-	if !srv.isClosed(err) {
-		err = errors.WithStack(err)
-	}
-	return err
+	return srv.serve([]boundListener{{
+		Listener: l,
+		tls:      true,
+		certFile: certFile,
+		keyFile:  keyFile,
+	}})
 }
 
 // ListenAndServeTLS is a wrapper for [http.Server.ListenAndServeTLS].
@@ -195,18 +304,14 @@ func (srv *Server) ListenAndServeTLS(certFile, keyFile string) error {
 	if err := srv.start(); err != nil {
 		return err
 	}
-
-	srv.log.LogServerStartTLS(srv.name, srv.Addr, certFile, keyFile)
-	err := srv.httpServer.ListenAndServeTLS(certFile, keyFile)
-	if !srv.isClosed(err) {
-		err = errors.WithStack(err)
-	}
-	return err
+	return srv.listenAndServe([]Endpoint{{Addr: srv.Addr, TLS: true}}, certFile, keyFile)
 }
 
 // isClosed checks if the provided error is http.ErrServerClosed, which
 // indicates the server has been successfully closed. In this case, state is
-// set to StateClosed and isClosed returns true.
+// set to StateClosed and isClosed returns true. When the server is still in
+// StateClosing, the state is left as is, so Shutdown or Close can complete
+// the transition to StateClosed.
 // If an error occurs while starting the internal server, state is set to
 // StateErrored and isClosed returns false.
 func (srv *Server) isClosed(err error) (ok bool) {
@@ -216,8 +321,18 @@ func (srv *Server) isClosed(err error) (ok bool) {
 	}
 
 	srv.mut.Lock()
-	srv.state = state
+	if ok && srv.state == StateClosing {
+		srv.mut.Unlock()
+		return ok
+	}
+
+	event, changed := srv.setState(state, err)
 	srv.mut.Unlock()
+	srv.emit(event, changed)
+
+	if l, lifecycle := srv.logger().(LifecycleLogger); lifecycle && !ok {
+		l.LogServerError(srv.name, err)
+	}
 	return ok
 }
 
@@ -229,52 +344,461 @@ func ShouldUseTLS(conf *tls.Config) bool {
 			conf.GetCertificate != nil)
 }
 
-// Run starts the server and calls either [Server.ListenAndServe] or
-// [Server.ListenAndServeTLS], depending on the provided TLS config/option(s).
-// Unlike [Server.Serve], [Server.ListenAndServe], [Server.ServeTLS], and
+// Run starts the server and listens on [Server.Addr] and any additional
+// [Endpoint]s. Connections on [Server.Addr] are served using TLS when
+// [ShouldUseTLS] reports the TLS config/option(s) are properly configured.
+// Each [Endpoint] decides for itself if TLS should be used. All endpoints
+// share the same [State], and are shut down or closed together with
+// [Server.Shutdown] or [Server.Close].
+// Run blocks until all endpoints have stopped serving and returns the first
+// fatal error, which causes any remaining endpoints to be closed. Unlike
+// [Server.Serve], [Server.ListenAndServe], [Server.ServeTLS], and
 // [Server.ListenAndServeTLS], Run will not return a [http.ErrServerClosed]
 // error when the server is closed.
+// When the server is run again, Run first waits until any connections that
+// remain from the previous run are closed.
 func (srv *Server) Run() error {
+	if err := srv.start(); err != nil {
+		return err
+	}
+	return srv.run()
+}
+
+// run is the part of Run that is executed after the server has started.
+func (srv *Server) run() error {
 	srv.mut.RLock()
-	useTLS := ShouldUseTLS(srv.TLSConfig)
+	eps := srv.runEndpoints()
 	srv.mut.RUnlock()
 
-	var err error
-	if useTLS {
-		err = srv.ListenAndServeTLS("", "")
-	} else {
-		err = srv.ListenAndServe()
+	err := srv.listenAndServe(eps, "", "")
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
 	}
-	if !errors.Is(err, http.ErrServerClosed) {
+	return err
+}
+
+// RunContext starts the server using [Server.Run] and blocks until ctx is
+// done, after which it gracefully shuts down the server. Just like
+// [Server.Shutdown], the shutdown is preceded by the drain period of
+// [Config.DrainDelay] and limited by [Config.ShutdownTimeout].
+// Any connections that remain open after this timeout are forcefully closed,
+// in which case the returned error contains a [ForcedCloseError] with the
+// number of closed connections.
+// RunContext returns immediately with the error from [Server.Run] when the
+// server stops before ctx is done.
+func (srv *Server) RunContext(ctx context.Context) error {
+	if err := srv.start(); err != nil {
 		return err
 	}
-	return nil
+
+	errs := make(chan error, 1)
+	go func() { errs <- srv.run() }()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	err := srv.shutdownRestarted(context.WithoutCancel(ctx), true)
+	if errors.Is(err, ErrUnableToShutdown) {
+		// server has stopped in the meantime
+		err = nil
+	}
+	return errors.Append(err, <-errs)
+}
+
+type boundListener struct {
+	net.Listener
+	tls      bool
+	certFile string
+	keyFile  string
+}
+
+// listenAndServe creates a [net.Listener] for each [Endpoint] and serves
+// them. Any already created listeners are closed when one of the [Endpoint]s
+// is unable to listen.
+func (srv *Server) listenAndServe(eps []Endpoint, certFile, keyFile string) error {
+	if !srv.beginServe() {
+		return http.ErrServerClosed
+	}
+
+	if len(eps) == 0 {
+		err := errors.New(ErrNoEndpoints)
+		srv.stopped(err)
+		return err
+	}
+
+	lns := make([]boundListener, 0, len(eps))
+	for _, ep := range eps {
+		l, err := ep.listen(srv.socketMode)
+		if err != nil {
+			for _, bl := range lns {
+				_ = bl.Close()
+			}
+			srv.releaseListeners(lns)
+			srv.stopped(err)
+			return err
+		}
+
+		lns = append(lns, boundListener{
+			Listener: l,
+			tls:      ep.TLS,
+			certFile: certFile,
+			keyFile:  keyFile,
+		})
+	}
+	return srv.serve(lns)
+}
+
+// serve serves all provided listeners concurrently until the internal
+// [http.Server] is shut down or closed, or one of the listeners returns a
+// fatal error. It returns [http.ErrServerClosed] or the first fatal error.
+// The listeners are kept open and served again when the [Server] is
+// restarted using [Server.Restart]. They are closed before the [Server] is
+// marked as stopped, so they can be bound again once Shutdown or Close
+// returns. Closed listeners owned by the [Server] are released, see
+// releaseListeners.
+func (srv *Server) serve(lns []boundListener) error {
+	if !srv.beginServe() {
+		for _, bl := range lns {
+			_ = bl.Close()
+		}
+		srv.releaseListeners(lns)
+		return http.ErrServerClosed
+	}
+
+	shared := make([]boundListener, 0, len(lns))
+	for _, bl := range lns {
+		bl.Listener = newSharedListener(bl.Listener)
+		shared = append(shared, bl)
+	}
+
+	srv.mut.Lock()
+	srv.listeners = make([]net.Listener, 0, len(lns))
+	for _, bl := range lns {
+		srv.listeners = append(srv.listeners, bl.Listener)
+	}
+	srv.mut.Unlock()
+
+	if l, ok := srv.logger().(LifecycleLogger); ok {
+		for _, bl := range lns {
+			l.LogServerListen(srv.name, bl.Addr())
+		}
+	}
+
+	for {
+		err := srv.serveShared(shared)
+		if errors.Is(err, http.ErrServerClosed) && srv.awaitRestart() {
+			continue
+		}
+
+		for _, bl := range shared {
+			_ = bl.Close()
+		}
+		srv.releaseListeners(lns)
+		srv.stopped(err)
+		return err
+	}
+}
+
+// serveShared serves a new acceptor for each of the [sharedListener]s until
+// the internal [http.Server] is shut down or closed. Any [generation]s that
+// are started by [Server.UpdateConfig] in the meantime, are served until then
+// as well.
+func (srv *Server) serveShared(shared []boundListener) error {
+	if err := srv.runHooks(context.Background(), StateStarted); err != nil {
+		return err
+	}
+
+	srv.mut.Lock()
+	srv.shared = shared
+	srv.serveGeneration(&srv.httpServer, srv.sig.ready)
+	srv.mut.Unlock()
+
+	srv.serving.Wait()
+
+	srv.mut.Lock()
+	err := srv.serveErr
+	srv.shared, srv.gens, srv.serveErr = nil, nil, nil
+	srv.mut.Unlock()
+
+	if err == nil {
+		err = http.ErrServerClosed
+	}
+	return err
+}
+
+// serveGeneration serves a new acceptor for each of the [sharedListener]s
+// using [http.Server] s. When ready is not nil, it is closed once s has
+// registered all acceptors and is about to accept connections, see
+// [readyListener]. The [Server]'s lock must be held when calling
+// serveGeneration.
+func (srv *Server) serveGeneration(s *http.Server, ready chan struct{}) *generation {
+	gen := &generation{Server: s}
+	if s != &srv.httpServer {
+		gen.trackConns(srv)
+	}
+
+	var notify func()
+	if ready != nil {
+		var pending atomic.Int32
+		pending.Store(int32(len(srv.shared)))
+		notify = func() {
+			if pending.Add(-1) == 0 {
+				close(ready)
+			}
+		}
+		if len(srv.shared) == 0 {
+			close(ready)
+		}
+	}
+
+	for _, bl := range srv.shared {
+		bl.Listener = bl.Listener.(*sharedListener).acceptor()
+		if srv.limiter.load().enabled() {
+			bl.Listener = newLimitListener(bl.Listener, srv)
+		}
+		if len(srv.proxyTrusted) != 0 {
+			bl.Listener = newProxyListener(bl.Listener, srv.proxyTrusted, s.ReadHeaderTimeout)
+		}
+		if notify != nil {
+			bl.Listener = &readyListener{Listener: bl.Listener, ready: notify}
+		}
+		gen.acceptors = append(gen.acceptors, bl.Listener)
+		gen.serving++
+
+		srv.serving.Add(1)
+		go func() { srv.serveDone(gen, srv.serveListener(s, bl)) }()
+	}
+	srv.gens = append(srv.gens, gen)
+	return gen
+}
+
+// serveDone is called when an acceptor of gen has stopped serving. The first
+// fatal error closes all [generation]s, so the remaining listeners stop
+// serving as well.
+func (srv *Server) serveDone(gen *generation, err error) {
+	defer srv.serving.Done()
+
+	srv.mut.Lock()
+	gen.serving--
+	if gen.retired.Load() && errors.Is(err, net.ErrClosed) {
+		// acceptor is closed by UpdateConfig
+		err = nil
+	}
+
+	var closeAll []*http.Server
+	if err != nil && !errors.Is(err, http.ErrServerClosed) && srv.serveErr == nil {
+		srv.serveErr = errors.WithStack(err)
+		closeAll = srv.servers()
+	}
+	srv.pruneGeneration(gen)
+	srv.mut.Unlock()
+
+	for _, s := range closeAll {
+		_ = s.Close()
+	}
+}
+
+func (srv *Server) serveListener(s *http.Server, bl boundListener) error {
+	addr := bl.Addr().String()
+	if bl.tls {
+		srv.log.LogServerStartTLS(srv.name, addr, bl.certFile, bl.keyFile)
+		return s.ServeTLS(bl.Listener, bl.certFile, bl.keyFile)
+	}
+
+	srv.log.LogServerStart(srv.name, addr)
+	return s.Serve(bl.Listener)
+}
+
+// stopped is called when the [Server] has stopped serving and its listeners
+// are closed. It records err as the reason for stopping and updates the
+// [State] using isClosed, before it releases any waiters of
+// [Server.WaitReady], [Server.Shutdown] and [Server.Close].
+func (srv *Server) stopped(err error) {
+	srv.mut.Lock()
+	sig := srv.sig
+	srv.listeners = nil
+	sig.err = err
+	srv.mut.Unlock()
+
+	srv.isClosed(err)
+	close(sig.done)
+}
+
+// beginServe marks the current run of the [Server] as serving, so
+// [Server.Shutdown] and [Server.Close] wait until it has stopped. It returns
+// false when the [Server] is shut down or closed before it began serving.
+func (srv *Server) beginServe() bool {
+	srv.mut.Lock()
+	defer srv.mut.Unlock()
+	if !srv.state.isRunning() {
+		return false
+	}
+	srv.sig.serving = true
+	return true
+}
+
+// waitStopped blocks until the current run of the [Server] has stopped
+// serving, see stopped.
+func (srv *Server) waitStopped() {
+	srv.mut.RLock()
+	sig := srv.sig
+	srv.mut.RUnlock()
+	if sig != nil && sig.serving {
+		<-sig.done
+	}
+}
+
+// waitSignals returns the [runSignals] of the current run of the [Server],
+// or of its next run when it is not running or restarting. The [Server]'s
+// lock must be held when calling waitSignals.
+func (srv *Server) waitSignals() *runSignals {
+	if srv.sig != nil && (srv.state.isRunning() || srv.restarting != nil) {
+		return srv.sig
+	}
+	if srv.nextSig == nil {
+		srv.nextSig = newRunSignals()
+	}
+	return srv.nextSig
+}
+
+// Ready returns a channel which is closed once the [Server] is started and
+// its listeners are bound and served by the internal [http.Server], and thus
+// ready to accept incoming connections.
+// A new channel is returned when the [Server] is not started, which is closed
+// once the [Server] is (re)started and ready.
+func (srv *Server) Ready() <-chan struct{} {
+	srv.mut.Lock()
+	defer srv.mut.Unlock()
+	return srv.waitSignals().ready
+}
+
+// WaitReady blocks until the [Server] is ready to accept incoming
+// connections, or ctx is done, in which case ctx's error is returned.
+// When the [Server] stops before becoming ready, or has already errored,
+// WaitReady returns the error that caused it to stop.
+func (srv *Server) WaitReady(ctx context.Context) error {
+	srv.mut.Lock()
+	if srv.state == StateErrored && srv.sig != nil {
+		err := srv.sig.err
+		srv.mut.Unlock()
+		return err
+	}
+	sig := srv.waitSignals()
+	srv.mut.Unlock()
+
+	select {
+	case <-sig.ready:
+		return nil
+	case <-sig.done:
+		srv.mut.RLock()
+		defer srv.mut.RUnlock()
+		return sig.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ListenAddr returns the [net.Addr] of the first listener the [Server]
+// accepts connections on. This is the actually bound address, e.g. when
+// [Server.Addr] is ":0". It returns nil when the [Server] is not ready, see
+// [Server.Ready].
+func (srv *Server) ListenAddr() net.Addr {
+	srv.mut.RLock()
+	defer srv.mut.RUnlock()
+	if len(srv.listeners) == 0 {
+		return nil
+	}
+	return srv.listeners[0].Addr()
+}
+
+// ListenAddrs returns the [net.Addr]s of all listeners the [Server] accepts
+// connections on. It returns nil when the [Server] is not ready, see
+// [Server.Ready].
+func (srv *Server) ListenAddrs() []net.Addr {
+	srv.mut.RLock()
+	defer srv.mut.RUnlock()
+	if len(srv.listeners) == 0 {
+		return nil
+	}
+
+	addrs := make([]net.Addr, 0, len(srv.listeners))
+	for _, l := range srv.listeners {
+		addrs = append(addrs, l.Addr())
+	}
+	return addrs
+}
+
+// renewChan returns ch when it is still open, or a new channel when ch is nil
+// or closed.
+func renewChan(ch chan struct{}) chan struct{} {
+	if ch != nil {
+		select {
+		case <-ch:
+		default:
+			return ch
+		}
+	}
+	return make(chan struct{})
 }
 
 // Shutdown gracefully shuts down the server without interrupting any active
-// connections. Just like the underlying [http.Server], Shutdown works by first
-// closing all open listeners, then closing all idle connections, and then
-// waiting indefinitely for connections to return to idle and then shut down.
+// connections. It first waits for any pending [Server.Restart] to complete.
+// When [Config.DrainDelay] is set, the [Server] then keeps serving for the
+// duration of the drain period, see [Server.Drain]. Next, the underlying
+// [http.Server] stops accepting new connections, closes all idle
+// connections, and waits indefinitely for active connections to return to
+// idle and then shut down. Finally, Shutdown waits until the [Server] has
+// stopped serving and its [net.Listener](s) are closed, so they can be bound
+// again once Shutdown returns.
 // If [Config.ShutdownTimeout] is set and/or the provided context expires before
-// the shutdown is complete, Shutdown returns the context's error. Otherwise, it
-// returns any error returned from closing the [Server]'s underlying
-// [net.Listener](s).
+// the shutdown is complete, Shutdown returns the context's error. The drain
+// period is cut short when ctx has a deadline, so the graceful shutdown keeps
+// its own budget. An [ErrUnableToDrain] error is returned when ctx is done
+// during the drain period, the [Server] is shut down regardless.
 // An [InvalidStateError] containing a [ErrUnableToShutdown] error is returned
 // when the server is not started.
 func (srv *Server) Shutdown(ctx context.Context) error {
-	if state := srv.State(); state != StateStarted {
+	return srv.shutdownRestarted(ctx, false)
+}
+
+// shutdown gracefully shuts down the server. When force is true, any
+// remaining connections are closed when ctx is done before the shutdown is
+// complete.
+func (srv *Server) shutdown(ctx context.Context, force bool) error {
+	err := srv.shutdownServers(ctx, force)
+	if errors.Is(err, ErrUnableToShutdown) {
+		return err
+	}
+	srv.waitStopped()
+	return errors.Append(err, srv.close(ctx))
+}
+
+// shutdownServers gracefully shuts down the internal [http.Server]s, similar
+// to shutdown, but leaves the [Server] in [StateClosing].
+func (srv *Server) shutdownServers(ctx context.Context, force bool) error {
+	srv.mut.Lock()
+	if state := srv.state; !state.isRunning() {
+		srv.mut.Unlock()
 		return errors.WithStack(&InvalidStateError{
 			Err:   ErrUnableToShutdown,
 			State: state,
 		})
 	}
 
-	srv.mut.Lock()
-	srv.state = StateClosing
+	start := time.Now()
+	event, ok := srv.setState(StateClosing, nil)
+	close(srv.shuttingDown)
 	srv.log.LogServerShutdown(srv.name)
-	srv.SetKeepAlivesEnabled(false)
+	servers := srv.servers()
+	for _, s := range servers {
+		s.SetKeepAlivesEnabled(false)
+	}
 	shutdownTimeout := srv.Config.ShutdownTimeout
 	srv.mut.Unlock()
+	srv.emit(event, ok)
 
 	if shutdownTimeout != 0 {
 		if t, ok := ctx.Deadline(); !ok || shutdownTimeout < time.Until(t) {
@@ -285,34 +809,102 @@ func (srv *Server) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	defer srv.close()
-	return errors.Wrap(srv.httpServer.Shutdown(ctx), ErrServerShutdown)
+	err := srv.runHooks(ctx, StateClosing)
+	e := eachServer(servers, func(s *http.Server) error {
+		return s.Shutdown(ctx)
+	})
+
+	log, _ := srv.logger().(LifecycleLogger)
+	if log != nil {
+		log.LogServerShutdownComplete(srv.name, time.Since(start), srv.stats.open.Load())
+	}
+	if e != nil {
+		err = errors.Append(err, errors.Wrap(e, ErrServerShutdown))
+		if force && ctx.Err() != nil {
+			err = errors.Append(err, srv.forceClose(servers, log))
+		}
+	}
+	return err
+}
+
+// forceClose closes any connections, including those that are hijacked, that
+// remain open after a graceful shutdown did not complete in time. The number
+// of closed connections is reported to log, when not nil.
+func (srv *Server) forceClose(servers []*http.Server, log LifecycleLogger) error {
+	n := srv.stats.open.Load()
+	if log != nil {
+		log.LogServerForcedClose(srv.name, n)
+	}
+	err := eachServer(servers, (*http.Server).Close)
+	srv.closeHijacked()
+	return errors.WithStack(&ForcedCloseError{Conns: n, Err: err})
+}
+
+// ForcedCloseError is returned by [Server.RunContext] when the [Server] is
+// not shut down gracefully within [Config.ShutdownTimeout], and any remaining
+// connections are forcefully closed.
+type ForcedCloseError struct {
+	// Conns is the number of connections that were forcefully closed.
+	Conns int64
+	// Err is the error returned while closing the [Server], if any.
+	Err error
+}
+
+func (f *ForcedCloseError) Unwrap() error { return f.Err }
+
+func (f *ForcedCloseError) Error() string {
+	msg := "forcefully closed " + strconv.FormatInt(f.Conns, 10) + " connection(s)"
+	if f.Err != nil {
+		msg += ": " + f.Err.Error()
+	}
+	return msg
 }
 
 // Close immediately closes all active [net.Listener](s) and any connections in
-// state [http.StateNew], [http.StateActive], or [http.StateIdle].
+// state [http.StateNew], [http.StateActive], or [http.StateIdle]. Hijacked
+// connections of which the handler is still running are closed as well.
+// Close waits for any pending [Server.Restart] to complete before closing.
 // An [InvalidStateError] containing a [ErrUnableToClose] error is returned
 // when the server is not started.
 // For a graceful shutdown, use [Server.Shutdown].
 func (srv *Server) Close() error {
-	if state := srv.State(); state != StateStarted {
+	return srv.afterRestart(ErrUnableToClose, srv.closeNow)
+}
+
+// closeNow immediately closes the server, see [Server.Close].
+func (srv *Server) closeNow() error {
+	srv.mut.Lock()
+	if state := srv.state; !state.isRunning() {
+		srv.mut.Unlock()
 		return errors.WithStack(&InvalidStateError{
 			Err:   ErrUnableToClose,
 			State: state,
 		})
 	}
 
-	srv.mut.Lock()
-	srv.state = StateClosing
+	event, ok := srv.setState(StateClosing, nil)
+	close(srv.shuttingDown)
 	srv.log.LogServerClose(srv.name)
+	servers := srv.servers()
 	srv.mut.Unlock()
-
-	defer srv.close()
-	return errors.Wrap(srv.httpServer.Close(), ErrServerClose)
+	srv.emit(event, ok)
+
+	ctx := context.Background()
+	err := srv.runHooks(ctx, StateClosing)
+	err = errors.Append(err, errors.Wrap(eachServer(servers, (*http.Server).Close), ErrServerClose))
+	srv.closeHijacked()
+	srv.waitStopped()
+	return errors.Append(err, srv.close(ctx))
 }
 
-func (srv *Server) close() {
+// close completes the transition to StateClosed and calls any OnClosed hooks.
+// These hooks are not affected by ctx being done, as they are called after
+// the server is completely closed.
+func (srv *Server) close(ctx context.Context) error {
 	srv.mut.Lock()
-	srv.state = StateClosed
+	event, ok := srv.setState(StateClosed, nil)
 	srv.mut.Unlock()
+	srv.emit(event, ok)
+
+	return srv.runHooks(context.WithoutCancel(ctx), StateClosed)
 }
@@ -5,8 +5,13 @@
 package serv
 
 import (
+	"bytes"
 	"context"
+	"log"
+	"net"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -61,3 +66,171 @@ func TestServer_State(t *testing.T) {
 		assert.ErrorIs(t, srv.Close(), ErrUnableToClose)
 	})
 }
+
+func TestServer_WaitReady(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		srv, err := New(WithHandler(DefaultServeMux()))
+		require.NoError(t, err)
+		srv.Addr = "127.0.0.1:0"
+		assert.Nil(t, srv.ListenAddr())
+
+		ready := srv.Ready()
+		go func() { _ = srv.Run() }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, srv.WaitReady(ctx))
+		<-ready
+
+		addr, ok := srv.ListenAddr().(*net.TCPAddr)
+		require.True(t, ok)
+		assert.NotZero(t, addr.Port)
+		assert.Equal(t, []net.Addr{addr}, srv.ListenAddrs())
+		assert.NoError(t, srv.Shutdown(context.Background()))
+	})
+	t.Run("shutdown when ready", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			srv := Server{Addr: "127.0.0.1:0"}
+			go func() { _ = srv.Run() }()
+			require.NoError(t, srv.WaitReady(context.Background()))
+
+			addr := srv.ListenAddr()
+			require.NotNil(t, addr, "should have a listen address when ready")
+			require.NoError(t, srv.Shutdown(context.Background()))
+
+			l, err := net.Listen(addr.Network(), addr.String())
+			require.NoError(t, err, "listener should be closed after shutdown")
+			_ = l.Close()
+		}
+	})
+	t.Run("listen error", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer l.Close()
+
+		srv := Server{Addr: l.Addr().String()}
+		go func() { _ = srv.Run() }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		assert.ErrorIs(t, srv.WaitReady(ctx), ErrListen)
+	})
+	t.Run("context done", func(t *testing.T) {
+		var srv Server
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, srv.WaitReady(ctx), context.Canceled)
+	})
+}
+
+func TestServer_Run_again(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	srv := Server{Addr: addr}
+	srv.Handler = http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	client := &http.Client{Transport: &http.Transport{}}
+	defer client.CloseIdleConnections()
+
+	for i := 0; i < 10; i++ {
+		done := make(chan error, 1)
+		go func() { done <- srv.Run() }()
+
+		require.NoError(t, srv.WaitReady(context.Background()), "run %d", i)
+		require.NotNil(t, srv.ListenAddr(), "run %d", i)
+		assert.Equal(t, addr, srv.ListenAddr().String())
+
+		// leave an idle connection open, which is closed by Shutdown
+		resp, err := client.Get("http://" + addr)
+		require.NoError(t, err, "run %d", i)
+		_ = resp.Body.Close()
+
+		require.NoError(t, srv.Shutdown(context.Background()), "run %d", i)
+		assert.Equal(t, StateClosed, srv.State())
+		assert.Nil(t, srv.ListenAddr(), "listeners should be closed after shutdown")
+
+		select {
+		case err = <-done:
+			require.NoError(t, err, "run %d", i)
+		case <-time.After(time.Second):
+			t.Fatalf("run %d: Run has not returned after Shutdown", i)
+		}
+	}
+}
+
+func TestServer_RunContext(t *testing.T) {
+	t.Run("graceful", func(t *testing.T) {
+		var buf bytes.Buffer
+		srv, err := New(WithLogger(NewLogger(log.New(&buf, "", 0))))
+		require.NoError(t, err)
+		srv.Addr = "127.0.0.1:0"
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan error, 1)
+		go func() { done <- srv.RunContext(ctx) }()
+		require.NoError(t, srv.WaitReady(context.Background()))
+		addr := srv.ListenAddr().String()
+
+		cancel()
+		assert.NoError(t, <-done)
+		assert.Equal(t, StateClosed, srv.State())
+		assert.Contains(t, buf.String(), "server listening on "+addr+"\n")
+		assert.Regexp(t, `server shut down in \S+ with 0 remaining connection\(s\)\n`, buf.String())
+	})
+	t.Run("forced close", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+
+		var buf bytes.Buffer
+		srv, err := New(
+			&Config{ShutdownTimeout: 50 * time.Millisecond},
+			WithLogger(NewLogger(log.New(&buf, "", 0))),
+			WithHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+				close(started)
+				<-release
+			})),
+		)
+		require.NoError(t, err)
+		srv.Addr = "127.0.0.1:0"
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- srv.RunContext(ctx) }()
+		require.NoError(t, srv.WaitReady(context.Background()))
+
+		go func() {
+			resp, err := http.Get("http://" + srv.ListenAddr().String())
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+		}()
+		<-started
+		cancel()
+
+		err = <-done
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		var fce *ForcedCloseError
+		require.ErrorAs(t, err, &fce)
+		assert.Equal(t, int64(1), fce.Conns)
+		assert.Equal(t, StateClosed, srv.State())
+		assert.Regexp(t, `server shut down in \S+ with 1 remaining connection\(s\)\n`, buf.String())
+		assert.Contains(t, buf.String(), "server forcefully closed 1 connection(s)\n")
+	})
+	t.Run("listen error", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer l.Close()
+
+		var buf bytes.Buffer
+		srv, err := New(WithLogger(NewLogger(log.New(&buf, "", 0))))
+		require.NoError(t, err)
+		srv.Addr = l.Addr().String()
+
+		assert.ErrorIs(t, srv.RunContext(context.Background()), ErrListen)
+		assert.Contains(t, buf.String(), "server errored: ")
+	})
+}
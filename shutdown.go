@@ -0,0 +1,59 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"net"
+)
+
+type ctxConnKey struct{}
+
+// connValue is added to the context of each connection that is served by a
+// [Server].
+type connValue struct {
+	conn         net.Conn
+	shuttingDown <-chan struct{}
+}
+
+func connValueFromContext(ctx context.Context) *connValue {
+	cv, _ := ctx.Value(ctxConnKey{}).(*connValue)
+	return cv
+}
+
+// ShuttingDown returns a channel which is closed once the [Server] that
+// serves the request of ctx begins to shut down or close. Long-lived
+// handlers, like those of server-sent events, long polling or websockets,
+// should end once the channel is closed, so they do not delay the shutdown
+// until [Config.ShutdownTimeout]. This also applies to handlers of
+// connections that are hijacked, which are not waited for by
+// [Server.Shutdown], but are closed by [Server.Close] and when any remaining
+// connections are forcefully closed.
+// The returned channel is nil, and thus never closed, when ctx does not
+// belong to a request that is served by a [Server].
+func ShuttingDown(ctx context.Context) <-chan struct{} {
+	if cv := connValueFromContext(ctx); cv != nil {
+		return cv.shuttingDown
+	}
+	return nil
+}
+
+// untrackHijacked stops tracking the connection of ctx when it is hijacked,
+// after the handler that hijacked it has returned. Its slot of the
+// connection limits is released as well.
+func (srv *Server) untrackHijacked(ctx context.Context) {
+	if cv := connValueFromContext(ctx); cv != nil && srv.stats.untrackHijacked(cv.conn) {
+		srv.limiter.release(cv.conn)
+	}
+}
+
+// closeHijacked closes all tracked hijacked connections, of which the
+// handlers have not yet returned.
+func (srv *Server) closeHijacked() {
+	srv.stats.hijackedConns.Range(func(conn, _ any) bool {
+		_ = conn.(net.Conn).Close()
+		return true
+	})
+}
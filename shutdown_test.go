@@ -0,0 +1,125 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShuttingDown(t *testing.T) {
+	t.Run("no server", func(t *testing.T) {
+		assert.Nil(t, ShuttingDown(context.Background()))
+	})
+
+	runServer := func(t *testing.T, handler http.HandlerFunc) (*Server, <-chan error) {
+		srv, err := New(WithHandler(handler))
+		require.NoError(t, err)
+		srv.Addr = "127.0.0.1:0"
+
+		done := make(chan error, 1)
+		go func() { done <- srv.Run() }()
+		require.NoError(t, srv.WaitReady(context.Background()))
+		return srv, done
+	}
+
+	t.Run("streaming", func(t *testing.T) {
+		started := make(chan struct{})
+		srv, done := runServer(t, func(wri http.ResponseWriter, req *http.Request) {
+			http.NewResponseController(wri).Flush()
+			close(started)
+			<-ShuttingDown(req.Context())
+			_, _ = io.WriteString(wri, "bye")
+		})
+
+		resp, err := http.Get("http://" + srv.ListenAddr().String())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(t, srv.Shutdown(ctx))
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "bye", string(body))
+		assert.NoError(t, <-done)
+	})
+
+	hijack := func(t *testing.T, wri http.ResponseWriter) net.Conn {
+		conn, buf, err := http.NewResponseController(wri).Hijack()
+		assert.NoError(t, err)
+		_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\n\r\n")
+		_ = buf.Flush()
+		return conn
+	}
+	dial := func(t *testing.T, srv *Server) (net.Conn, *bufio.Reader) {
+		conn, err := net.Dial("tcp", srv.ListenAddr().String())
+		require.NoError(t, err)
+		_, err = io.WriteString(conn, "GET / HTTP/1.1\r\nHost: test\r\n\r\n")
+		require.NoError(t, err)
+
+		r := bufio.NewReader(conn)
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		assert.Contains(t, line, "101")
+		_, _ = r.ReadString('\n')
+		return conn, r
+	}
+
+	t.Run("hijacked", func(t *testing.T) {
+		srv, done := runServer(t, func(wri http.ResponseWriter, req *http.Request) {
+			conn := hijack(t, wri)
+			defer conn.Close()
+			<-ShuttingDown(req.Context())
+			_, _ = io.WriteString(conn, "bye")
+		})
+
+		conn, r := dial(t, srv)
+		defer conn.Close()
+		require.Eventually(t, func() bool {
+			return srv.Stats().HijackedActive == 1
+		}, time.Second, time.Millisecond)
+
+		require.NoError(t, srv.Shutdown(context.Background()))
+		msg, _ := io.ReadAll(r)
+		assert.Equal(t, "bye", string(msg))
+		assert.NoError(t, <-done)
+
+		assert.Eventually(t, func() bool {
+			return srv.Stats().HijackedActive == 0
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("close hijacked", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		srv, done := runServer(t, func(wri http.ResponseWriter, _ *http.Request) {
+			_ = hijack(t, wri)
+			<-release
+		})
+
+		conn, r := dial(t, srv)
+		defer conn.Close()
+		require.Eventually(t, func() bool {
+			return srv.Stats().HijackedActive == 1
+		}, time.Second, time.Millisecond)
+
+		require.NoError(t, srv.Close())
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, err := r.ReadByte()
+		assert.ErrorIs(t, err, io.EOF, "connection should be closed by server")
+		assert.NoError(t, <-done)
+	})
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"log"
+	"log/slog"
+	"net"
+	"time"
+)
+
+const panicNewNilSlogLogger = "serv.NewSlogLogger: slog.Logger should not be nil"
+
+// NewSlogLogger returns an [ErrorLogger] that uses a [slog.Logger] to log the
+// [Server]'s lifecycle events as structured records, with attributes like
+// the server's name, address and TLS usage. It implements all optional
+// logger interfaces of this package.
+// Its [ErrorLoggerProvider.ErrorLogger] bridges the messages of
+// [http.Server.ErrorLog] to l, at a level based on the message. E.g. TLS
+// handshake errors, which are mostly caused by misbehaving clients, are
+// logged at [slog.LevelDebug], while other errors are logged at
+// [slog.LevelError].
+func NewSlogLogger(l *slog.Logger) ErrorLogger {
+	if l == nil {
+		panic(panicNewNilSlogLogger)
+	}
+	return &slogLogger{
+		Logger:   l,
+		errorLog: log.New(&slogWriter{l}, "", 0),
+	}
+}
+
+type slogLogger struct {
+	*slog.Logger
+	errorLog *log.Logger
+}
+
+var (
+	_ LifecycleLogger = (*slogLogger)(nil)
+	_ UpgradeLogger   = (*slogLogger)(nil)
+	_ ConfigLogger    = (*slogLogger)(nil)
+	_ LimitLogger     = (*slogLogger)(nil)
+	_ CertLogger      = (*slogLogger)(nil)
+)
+
+func (l *slogLogger) ErrorLogger() *log.Logger { return l.errorLog }
+
+func (l *slogLogger) log(level slog.Level, msg, name string, attrs ...slog.Attr) {
+	if name != "" {
+		attrs = append([]slog.Attr{slog.String("server", name)}, attrs...)
+	}
+	l.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+func (l *slogLogger) LogServerStart(name, addr string) {
+	l.log(slog.LevelInfo, "server starting", name,
+		slog.String("addr", addr),
+		slog.Bool("tls", false),
+	)
+}
+
+func (l *slogLogger) LogServerStartTLS(name, addr, certFile, keyFile string) {
+	attrs := []slog.Attr{
+		slog.String("addr", addr),
+		slog.Bool("tls", true),
+	}
+	if certFile != "" && keyFile != "" {
+		attrs = append(attrs,
+			slog.String("cert_file", certFile),
+			slog.String("key_file", keyFile),
+		)
+	}
+	l.log(slog.LevelInfo, "server starting", name, attrs...)
+}
+
+func (l *slogLogger) LogServerShutdown(name string) {
+	l.log(slog.LevelInfo, "server shutting down", name)
+}
+
+func (l *slogLogger) LogServerClose(name string) {
+	l.log(slog.LevelInfo, "server closing", name)
+}
+
+func (l *slogLogger) LogServerListen(name string, addr net.Addr) {
+	l.log(slog.LevelInfo, "server listening", name,
+		slog.String("network", addr.Network()),
+		slog.String("addr", addr.String()),
+	)
+}
+
+func (l *slogLogger) LogServerError(name string, err error) {
+	l.log(slog.LevelError, "server errored", name, slog.String("error", err.Error()))
+}
+
+func (l *slogLogger) LogServerShutdownComplete(name string, dur time.Duration, conns int64) {
+	level := slog.LevelInfo
+	if conns != 0 {
+		level = slog.LevelWarn
+	}
+	l.log(level, "server shut down", name,
+		slog.Duration("duration", dur),
+		slog.Int64("conns", conns),
+	)
+}
+
+func (l *slogLogger) LogServerForcedClose(name string, conns int64) {
+	l.log(slog.LevelWarn, "server forcefully closed connections", name, slog.Int64("conns", conns))
+}
+
+func (l *slogLogger) LogServerUpgrade(name string, pid int) {
+	l.log(slog.LevelInfo, "server upgraded", name, slog.Int("pid", pid))
+}
+
+func (l *slogLogger) LogServerUpgradeError(name string, err error) {
+	l.log(slog.LevelError, "server failed to upgrade", name, slog.String("error", err.Error()))
+}
+
+func (l *slogLogger) LogServerConfig(name string, cfg Config) {
+	fields := cfg.fields()
+	attrs := make([]any, 0, len(fields))
+	for _, f := range fields {
+		attrs = append(attrs, configAttr(f.key(), f.value))
+	}
+	l.log(slog.LevelInfo, "server config", name, slog.Group("config", attrs...))
+}
+
+func (l *slogLogger) LogServerConfigUpdate(name string, old, cfg Config) {
+	oldFields := old.fields()
+	var attrs []any
+	for i, f := range cfg.fields() {
+		if oldFields[i].value.String() == f.value.String() {
+			continue
+		}
+		attrs = append(attrs, slog.Group(f.key(),
+			configAttr("from", oldFields[i].value),
+			configAttr("to", f.value),
+		))
+	}
+	l.log(slog.LevelInfo, "server config updated", name, slog.Group("config", attrs...))
+}
+
+func (l *slogLogger) LogServerConfigUpdateError(name string, err error) {
+	l.log(slog.LevelError, "server failed to update config", name, slog.String("error", err.Error()))
+}
+
+func (l *slogLogger) LogServerConnsRejected(name string, n uint64, reason error) {
+	l.log(slog.LevelWarn, "server rejected connections", name,
+		slog.Uint64("conns", n),
+		slog.String("reason", reason.Error()),
+	)
+}
+
+func (l *slogLogger) LogServerCertReload(name, certFile string) {
+	l.log(slog.LevelInfo, "server reloaded certificate", name, slog.String("cert_file", certFile))
+}
+
+func (l *slogLogger) LogServerCertReloadError(name, certFile string, err error) {
+	l.log(slog.LevelError, "server failed to reload certificate", name,
+		slog.String("cert_file", certFile),
+		slog.String("error", err.Error()),
+	)
+}
+
+// configAttr returns a [slog.Attr] for the value of a [Config] field.
+// Durations are logged as [slog.Duration] values, other values as strings.
+func configAttr(key string, val flag.Value) slog.Attr {
+	if d, ok := val.(*durationValue); ok {
+		return slog.Duration(key, time.Duration(*d))
+	}
+	return slog.String(key, val.String())
+}
+
+// errorLogLevels are the levels of messages written to [http.Server.ErrorLog]
+// which should not be logged at [slog.LevelError], based on their prefix.
+var errorLogLevels = []struct {
+	prefix []byte
+	level  slog.Level
+}{
+	{[]byte("http: TLS handshake error"), slog.LevelDebug},
+	{[]byte("http2: "), slog.LevelDebug},
+	{[]byte("http: superfluous response.WriteHeader"), slog.LevelWarn},
+	{[]byte("http: response.WriteHeader on hijacked connection"), slog.LevelWarn},
+	{[]byte("http: response.Write on hijacked connection"), slog.LevelWarn},
+	{[]byte("http: URL query contains semicolon"), slog.LevelWarn},
+	{[]byte("http: Accept error"), slog.LevelWarn},
+}
+
+// slogWriter writes the messages of a [log.Logger] to a [slog.Logger].
+type slogWriter struct{ *slog.Logger }
+
+func (w *slogWriter) Write(p []byte) (int, error) {
+	msg := bytes.TrimRight(p, "\n")
+
+	level := slog.LevelError
+	for _, el := range errorLogLevels {
+		if bytes.HasPrefix(msg, el.prefix) {
+			level = el.level
+			break
+		}
+	}
+
+	w.Log(context.Background(), level, string(msg))
+	return len(p), nil
+}
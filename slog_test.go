@@ -0,0 +1,119 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"bytes"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSlogLogger(buf *bytes.Buffer) ErrorLogger {
+	return NewSlogLogger(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+}
+
+func TestNewSlogLogger(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		assert.PanicsWithValue(t, panicNewNilSlogLogger, func() { _ = NewSlogLogger(nil) })
+	})
+
+	tests := map[string]struct {
+		log  func(l ErrorLogger)
+		want string
+	}{
+		"start": {
+			log:  func(l ErrorLogger) { l.LogServerStart("foo", "127.0.0.1:80") },
+			want: `level=INFO msg="server starting" server=foo addr=127.0.0.1:80 tls=false`,
+		},
+		"start tls": {
+			log: func(l ErrorLogger) { l.LogServerStartTLS("foo", "127.0.0.1:443", "cert.pem", "key.pem") },
+			want: `level=INFO msg="server starting" server=foo addr=127.0.0.1:443 tls=true ` +
+				`cert_file=cert.pem key_file=key.pem`,
+		},
+		"shutdown without name": {
+			log:  func(l ErrorLogger) { l.LogServerShutdown("") },
+			want: `level=INFO msg="server shutting down"`,
+		},
+		"listen": {
+			log: func(l ErrorLogger) {
+				l.(LifecycleLogger).LogServerListen("foo", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 80})
+			},
+			want: `level=INFO msg="server listening" server=foo network=tcp addr=127.0.0.1:80`,
+		},
+		"shutdown complete": {
+			log: func(l ErrorLogger) {
+				l.(LifecycleLogger).LogServerShutdownComplete("foo", 1500*time.Millisecond, 2)
+			},
+			want: `level=WARN msg="server shut down" server=foo duration=1.5s conns=2`,
+		},
+		"upgrade error": {
+			log:  func(l ErrorLogger) { l.(UpgradeLogger).LogServerUpgradeError("foo", errors.New("oops")) },
+			want: `level=ERROR msg="server failed to upgrade" server=foo error=oops`,
+		},
+		"config": {
+			log: func(l ErrorLogger) {
+				l.(ConfigLogger).LogServerConfig("foo", Config{ReadTimeout: time.Second, MaxHeaderBytes: 4096})
+			},
+			want: `level=INFO msg="server config" server=foo config.read_timeout=1s ` +
+				`config.read_header_timeout=0s config.write_timeout=0s config.idle_timeout=0s ` +
+				`config.shutdown_timeout=0s config.drain_delay=0s config.max_header_bytes=4KiB ` +
+				`config.max_conns=0 config.max_conns_per_ip=0 config.max_accept_rate=0 ` +
+				`config.limit_policy=reject ` +
+				`config.protocols=default config.http2_max_concurrent_streams=0 ` +
+				`config.http2_max_read_frame_size=0 config.http2_send_ping_timeout=0s ` +
+				`config.http2_ping_timeout=0s`,
+		},
+		"config update": {
+			log: func(l ErrorLogger) {
+				l.(ConfigLogger).LogServerConfigUpdate("foo",
+					Config{ReadTimeout: time.Second},
+					Config{ReadTimeout: 2 * time.Second, MaxConns: 10},
+				)
+			},
+			want: `level=INFO msg="server config updated" server=foo ` +
+				`config.read_timeout.from=1s config.read_timeout.to=2s ` +
+				`config.max_conns.from=0 config.max_conns.to=10`,
+		},
+		"cert reload": {
+			log:  func(l ErrorLogger) { l.(CertLogger).LogServerCertReload("foo", "cert.pem") },
+			want: `level=INFO msg="server reloaded certificate" server=foo cert_file=cert.pem`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tc.log(newTestSlogLogger(&buf))
+			assert.Equal(t, tc.want+"\n", buf.String())
+		})
+	}
+}
+
+func TestSlogLogger_ErrorLogger(t *testing.T) {
+	tests := map[string]string{
+		"http: TLS handshake error from 127.0.0.1:1234: EOF": "DEBUG",
+		"http: superfluous response.WriteHeader call":        "WARN",
+		"http: panic serving 127.0.0.1:1234: runtime error":  "ERROR",
+	}
+	for msg, level := range tests {
+		t.Run(msg, func(t *testing.T) {
+			var buf bytes.Buffer
+			newTestSlogLogger(&buf).ErrorLogger().Println(msg)
+			assert.Equal(t, "level="+level+" msg=\""+msg+"\"\n", buf.String())
+		})
+	}
+}
@@ -14,7 +14,8 @@ const (
 	// StateUnstarted is the default state of a [Server] when it is created.
 	StateUnstarted State = iota
 	// StateStarted indicates the [Server] is (almost) ready to start listening
-	// for incoming connections.
+	// for incoming connections. Use [Server.Ready] or [Server.WaitReady] to
+	// wait until it actually accepts incoming connections.
 	StateStarted
 	// StateErrored indicates the [Server] has encountered an error while
 	// listening for incoming connections.
@@ -25,6 +26,9 @@ const (
 	// StateClosed indicates the [Server] has been completely closed and is no
 	// longer listening for incoming connections.
 	StateClosed
+	// StatePaused indicates the [Server] is started but temporarily does not
+	// handle any new requests, see [Server.Pause].
+	StatePaused
 )
 
 func (s State) String() string {
@@ -39,12 +43,18 @@ func (s State) String() string {
 		return "closing"
 	case StateClosed:
 		return "closed"
+	case StatePaused:
+		return "paused"
 
 	default:
 		panic(fmt.Sprintf("serv: %d is not a valid State", s))
 	}
 }
 
+// isRunning indicates the [State] is [StateStarted] or [StatePaused], in
+// which the [Server] serves its listeners.
+func (s State) isRunning() bool { return s == StateStarted || s == StatePaused }
+
 // InvalidStateError is returned when an operation is attempted on a [Server]
 // that is in an invalid state for that operation to succeed.
 type InvalidStateError struct {
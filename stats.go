@@ -0,0 +1,73 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"net/http"
+	"time"
+)
+
+// Stats contains live statistics of a [Server], see [Server.Stats].
+type Stats struct {
+	// State is the current [State] of the [Server].
+	State State
+	// StateChanged is the time of the last [State] change.
+	StateChanged time.Time
+	// Uptime is the duration since the [Server] has (re)started. It is zero
+	// when the [Server] is not started.
+	Uptime time.Duration
+	// Conns is the number of open connections.
+	Conns int64
+	// New is the number of open connections in [http.StateNew].
+	New int64
+	// Active is the number of open connections in [http.StateActive].
+	Active int64
+	// Idle is the number of open connections in [http.StateIdle].
+	Idle int64
+	// Accepted is the total number of accepted connections.
+	Accepted uint64
+	// Hijacked is the total number of hijacked connections.
+	Hijacked uint64
+	// HijackedActive is the number of hijacked connections of which the
+	// handler is still running, e.g. websockets.
+	HijackedActive int64
+	// Rejected is the total number of connections that are rejected because
+	// they exceed one of the connection limits of [Config].
+	Rejected uint64
+	// Requests is the number of requests that are currently being handled.
+	Requests int64
+	// Draining is the number of connections that remain open while the
+	// [Server] is shutting down. It is zero in any other [State].
+	Draining int64
+}
+
+// Stats returns the current [Stats] of the [Server]. The connection and
+// request counters are maintained using atomic operations, so collecting
+// them does not interfere with serving requests.
+func (srv *Server) Stats() Stats {
+	srv.mut.RLock()
+	stats := Stats{
+		State:        srv.state,
+		StateChanged: srv.event.Time,
+	}
+	if srv.state.isRunning() {
+		stats.Uptime = time.Since(srv.started)
+	}
+	srv.mut.RUnlock()
+
+	stats.Conns = srv.stats.open.Load()
+	stats.New = srv.stats.states[http.StateNew].Load()
+	stats.Active = srv.stats.states[http.StateActive].Load()
+	stats.Idle = srv.stats.states[http.StateIdle].Load()
+	stats.Accepted = srv.stats.accepted.Load()
+	stats.Hijacked = srv.stats.hijacked.Load()
+	stats.HijackedActive = srv.stats.hijackedActive.Load()
+	stats.Rejected = srv.limiter.rejected.Load()
+	stats.Requests = srv.stats.requests.Load()
+	if stats.State == StateClosing {
+		stats.Draining = stats.Conns
+	}
+	return stats
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Stats(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/block", func(http.ResponseWriter, *http.Request) {
+		started <- struct{}{}
+		<-release
+	})
+	mux.HandleFunc("/hijack", func(w http.ResponseWriter, _ *http.Request) {
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	})
+
+	srv, err := New(WithHandler(mux))
+	require.NoError(t, err)
+	srv.Addr = "127.0.0.1:0"
+
+	stats := srv.Stats()
+	assert.Equal(t, StateUnstarted, stats.State)
+	assert.Zero(t, stats.Uptime)
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Run() }()
+	require.NoError(t, srv.WaitReady(context.Background()))
+
+	stats = srv.Stats()
+	assert.Equal(t, StateStarted, stats.State)
+	assert.Positive(t, stats.Uptime)
+	assert.False(t, stats.StateChanged.IsZero())
+
+	eventually := func(fn func(s Stats) bool) {
+		t.Helper()
+		assert.Eventually(t, func() bool { return fn(srv.Stats()) }, time.Second, time.Millisecond)
+	}
+
+	dial := func(path string) (net.Conn, *bufio.Reader) {
+		conn, err := net.Dial("tcp", srv.ListenAddr().String())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+
+		_, err = io.WriteString(conn, "GET "+path+" HTTP/1.1\r\nHost: localhost\r\n\r\n")
+		require.NoError(t, err)
+		return conn, bufio.NewReader(conn)
+	}
+
+	_, r := dial("/block")
+	<-started
+	eventually(func(s Stats) bool {
+		return s.Conns == 1 && s.Active == 1 && s.Requests == 1 && s.Accepted == 1
+	})
+
+	release <- struct{}{}
+	resp, err := http.ReadResponse(r, nil)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	eventually(func(s Stats) bool {
+		return s.Conns == 1 && s.Idle == 1 && s.Active == 0 && s.Requests == 0
+	})
+
+	dial("/hijack")
+	eventually(func(s Stats) bool {
+		return s.Hijacked == 1 && s.Accepted == 2 && s.Conns == 1
+	})
+
+	dial("/block")
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- srv.Shutdown(context.Background()) }()
+	eventually(func(s Stats) bool {
+		return s.State == StateClosing && s.Draining == 1 && s.Active == 1
+	})
+
+	close(release)
+	assert.NoError(t, <-shutdown)
+	assert.NoError(t, <-done)
+
+	stats = srv.Stats()
+	assert.Equal(t, StateClosed, stats.State)
+	assert.Zero(t, stats.Draining)
+	assert.Zero(t, stats.Uptime)
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-pogo/errors"
+)
+
+const (
+	ErrUpgradeInProgress errors.Msg = "upgrade is already in progress"
+	ErrUpgradeFailed     errors.Msg = "unable to upgrade"
+	ErrUpgradeNotReady   errors.Msg = "upgraded process exited before it was ready"
+	ErrUpgradeCompleted  errors.Msg = "upgrade is already completed"
+	ErrNoUpgradeSockets  errors.Msg = "no listening sockets to pass to the upgraded process"
+)
+
+// Upgrader performs a zero-downtime upgrade of the running binary. It starts a
+// new process of the current executable, passes the listening sockets of its
+// [Server]s to it, waits for the new process to report it is ready, and then
+// gracefully shuts down its [Server]s using [Server.Shutdown].
+//
+// The new process should use [Server.Run] to start its [Server]s, which
+// automatically accepts connections on the inherited sockets of matching
+// addresses, and call [Upgrader.Ready] once it is ready to take over.
+// Upgrading is not supported on Windows.
+type Upgrader struct {
+	servers []*Server
+	mut     sync.Mutex
+	exit    chan struct{}
+	once    sync.Once
+	// args are the arguments passed to the new process, defaults to
+	// os.Args[1:] when nil
+	args []string
+}
+
+// NewUpgrader creates a new [Upgrader] for the provided [Server]s.
+func NewUpgrader(servers ...*Server) *Upgrader {
+	return &Upgrader{
+		servers: servers,
+		exit:    make(chan struct{}),
+	}
+}
+
+// Ready notifies the parent process, which started the current process using
+// [Upgrader.Upgrade], that it is ready to accept connections. It does nothing
+// when the current process is not started by an [Upgrader].
+func (upg *Upgrader) Ready() error {
+	if _, err := InheritedListeners(); err != nil {
+		return err
+	}
+
+	inherited.mut.Lock()
+	defer inherited.mut.Unlock()
+
+	if inherited.ready == nil {
+		return nil
+	}
+
+	_, err := inherited.ready.Write([]byte{1})
+	err = errors.Append(err, inherited.ready.Close())
+	inherited.ready = nil
+	return errors.WithStack(err)
+}
+
+// Exit returns a channel which is closed after a successful upgrade, once all
+// [Server]s are shut down. The current process should exit afterward.
+func (upg *Upgrader) Exit() <-chan struct{} { return upg.exit }
+
+// Upgrade starts a new process of the current executable with the same
+// arguments, and passes the listening sockets of all started [Server]s to it.
+// It then waits until the new process calls [Upgrader.Ready] before shutting
+// down the [Server]s. The new process is killed when ctx is done before it is
+// ready, and the [Server]s continue to serve as usual.
+// An [ErrUpgradeInProgress] error is returned when another upgrade is in
+// progress, and an [ErrUpgradeCompleted] error when a previous upgrade already
+// succeeded. Upgrading fails with [ErrNoUpgradeSockets] when none of the
+// [Server]s has a listening socket to pass to the new process.
+func (upg *Upgrader) Upgrade(ctx context.Context) error {
+	if !upg.mut.TryLock() {
+		return errors.New(ErrUpgradeInProgress)
+	}
+	defer upg.mut.Unlock()
+
+	select {
+	case <-upg.exit:
+		return errors.New(ErrUpgradeCompleted)
+	default:
+	}
+
+	files, names, err := upg.files()
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	if err != nil {
+		return upg.failed(err)
+	}
+	if len(files) == 0 {
+		return upg.failed(errors.New(ErrNoUpgradeSockets))
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return upg.failed(err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return upg.failed(err)
+	}
+	defer r.Close()
+
+	args := upg.args
+	if args == nil {
+		args = os.Args[1:]
+	}
+
+	cmd := exec.Command(exe, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = append(files, w)
+	cmd.Env = append(upgradeEnviron(),
+		envListenFDs+"="+strconv.Itoa(len(files)),
+		envListenFDNames+"="+strings.Join(names, ":"),
+		envUpgradePPID+"="+strconv.Itoa(os.Getpid()),
+	)
+
+	err = cmd.Start()
+	_ = w.Close()
+	if err != nil {
+		return upg.failed(err)
+	}
+
+	ready := make(chan error, 1)
+	go func() {
+		var buf [1]byte
+		_, err := r.Read(buf[:])
+		ready <- err
+	}()
+
+	select {
+	case err = <-ready:
+		if err != nil {
+			// pipe is closed without writing to it
+			err = errors.New(ErrUpgradeNotReady)
+		}
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		go func() { _ = cmd.Wait() }()
+		return upg.failed(err)
+	}
+
+	pid := cmd.Process.Pid
+	for _, srv := range upg.servers {
+		if l, ok := srv.logger().(UpgradeLogger); ok {
+			l.LogServerUpgrade(srv.Name(), pid)
+		}
+	}
+	for _, srv := range upg.servers {
+		// socket files are used by the new process
+		srv.keepSocketFiles()
+		if !srv.State().isRunning() {
+			continue
+		}
+		err = errors.Append(err, srv.Shutdown(ctx))
+	}
+
+	upg.once.Do(func() { close(upg.exit) })
+	return err
+}
+
+// UpgradeOnSignal blocks and calls [Upgrader.Upgrade] each time one of the
+// provided signals is received, until the upgrade succeeds or ctx is done.
+// Failed upgrades are logged using the [Server]s' [UpgradeLogger], if
+// available.
+func (upg *Upgrader) UpgradeOnSignal(ctx context.Context, sig ...os.Signal) error {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig...)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ch:
+			// failures are logged, wait for the next signal to try again
+			err := upg.Upgrade(ctx)
+			select {
+			case <-upg.exit:
+				return err
+			default:
+			}
+
+		case <-upg.exit:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (upg *Upgrader) failed(err error) error {
+	err = errors.Wrap(err, ErrUpgradeFailed)
+	for _, srv := range upg.servers {
+		if l, ok := srv.logger().(UpgradeLogger); ok {
+			l.LogServerUpgradeError(srv.Name(), err)
+		}
+	}
+	return err
+}
+
+type filer interface {
+	File() (*os.File, error)
+}
+
+// files returns duplicates of the file descriptors of the listeners of all
+// started [Server]s, and their names which are passed using LISTEN_FDNAMES.
+func (upg *Upgrader) files() ([]*os.File, []string, error) {
+	var files []*os.File
+	var names []string
+	for _, srv := range upg.servers {
+		srv.mut.RLock()
+		lns := srv.listeners
+		name := srv.name
+		srv.mut.RUnlock()
+
+		if name == "" {
+			name = "unknown"
+		} else {
+			name = strings.ReplaceAll(name, ":", "_")
+		}
+
+		for _, l := range lns {
+			f, err := listenerFile(l)
+			if err != nil {
+				return files, names, err
+			}
+			files = append(files, f)
+			names = append(names, name)
+		}
+	}
+	return files, names, nil
+}
+
+func listenerFile(l net.Listener) (*os.File, error) {
+	if f, ok := l.(filer); ok {
+		file, err := f.File()
+		return file, errors.WithStack(err)
+	}
+	return nil, errors.Newf("listener %T does not provide a file", l)
+}
+
+// upgradeEnviron returns the environment of the current process, without any
+// variables used for passing listeners.
+func upgradeEnviron() []string {
+	env := os.Environ()
+	res := env[:0:0]
+	for _, kv := range env {
+		switch kv[:strings.IndexByte(kv+"=", '=')] {
+		case envListenPID, envListenFDs, envListenFDNames, envUpgradePPID:
+			continue
+		}
+		res = append(res, kv)
+	}
+	return res
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serv
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envUpgradeHelper = "SERV_TEST_UPGRADE_HELPER"
+
+func TestUpgrader_Upgrade(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("upgrading is not supported on windows")
+	}
+
+	handler := func(body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Connection", "close")
+			_, _ = w.Write([]byte(body))
+		})
+	}
+	get := func(addr string) string {
+		resp, err := http.Get("http://" + addr)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	srv, err := New(Endpoint{Addr: "127.0.0.1:0"}, WithHandler(handler("parent")))
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Run() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	require.NoError(t, srv.WaitReady(ctx))
+	addr := srv.ListenAddr().String()
+	assert.Equal(t, "parent", get(addr))
+
+	t.Setenv(envUpgradeHelper, addr)
+	upg := NewUpgrader(srv)
+	upg.args = []string{"-test.run=^TestUpgraderHelper$"}
+
+	require.NoError(t, upg.Upgrade(ctx))
+	assert.NoError(t, <-done)
+	assert.Equal(t, StateClosed, srv.State())
+
+	select {
+	case <-upg.Exit():
+	default:
+		t.Fatal("exit channel should be closed")
+	}
+
+	assert.Equal(t, "child", get(addr))
+	assert.ErrorIs(t, upg.Upgrade(ctx), ErrUpgradeCompleted)
+}
+
+func TestUpgrader_Upgrade_no_sockets(t *testing.T) {
+	srv, err := New(Endpoint{Addr: "127.0.0.1:0"})
+	require.NoError(t, err)
+
+	err = NewUpgrader(srv).Upgrade(context.Background())
+	assert.ErrorIs(t, err, ErrUpgradeFailed)
+	assert.ErrorIs(t, err, ErrNoUpgradeSockets)
+}
+
+func TestUpgraderHelper(t *testing.T) {
+	addr := os.Getenv(envUpgradeHelper)
+	if addr == "" {
+		t.Skip("helper process")
+	}
+
+	var srv *Server
+	srv, err := New(
+		Endpoint{Addr: addr},
+		WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("child"))
+			go func() { _ = srv.Shutdown(context.Background()) }()
+		})),
+	)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Run() }()
+
+	require.NoError(t, NewUpgrader(srv).Ready())
+	assert.NoError(t, <-done)
+}
+
+func TestUpgrader_Ready(t *testing.T) {
+	// not started by an Upgrader
+	assert.NoError(t, NewUpgrader().Ready())
+}